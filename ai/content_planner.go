@@ -7,12 +7,16 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
 type ContentPlanner struct {
 	apiKey string
 	token  string
+	// Токен GigaChat живёт 30 минут
+	tokenExpires time.Time
+	mu           sync.Mutex
 }
 
 func NewContentPlanner(apiKey string) *ContentPlanner {
@@ -40,7 +44,7 @@ type DayPlan struct {
 
 // Получение токена GigaChat
 func (cp *ContentPlanner) getToken() error {
-	if cp.token != "" {
+	if cp.token != "" && time.Now().Before(cp.tokenExpires) {
 		return nil
 	}
 
@@ -60,14 +64,24 @@ func (cp *ContentPlanner) getToken() error {
 
 	var result struct {
 		AccessToken string `json:"access_token"`
+		ExpiresAt   int64  `json:"expires_at"` // в миллисекундах
 	}
 	json.NewDecoder(resp.Body).Decode(&result)
+	if result.AccessToken == "" {
+		return fmt.Errorf("GigaChat не выдал токен (HTTP %d)", resp.StatusCode)
+	}
 	cp.token = result.AccessToken
+	// Обновляем за минуту до истечения
+	cp.tokenExpires = time.UnixMilli(result.ExpiresAt).Add(-time.Minute)
 	return nil
 }
 
 func (cp *ContentPlanner) GenerateContentPlan(req ContentPlanRequest) ([]DayPlan, error) {
-	if err := cp.getToken(); err != nil {
+	cp.mu.Lock()
+	err := cp.getToken()
+	token := cp.token
+	cp.mu.Unlock()
+	if err != nil {
 		return nil, fmt.Errorf("ошибка авторизации: %v", err)
 	}
 
@@ -114,7 +128,7 @@ func (cp *ContentPlanner) GenerateContentPlan(req ContentPlanRequest) ([]DayPlan
 	httpReq, _ := http.NewRequest("POST", "https://gigachat.devices.sberbank.ru/api/v1/chat/completions",
 		bytes.NewBuffer(jsonBody))
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+cp.token)
+	httpReq.Header.Set("Authorization", "Bearer "+token)
 
 	client := &http.Client{Timeout: 60 * time.Second}
 	resp, err := client.Do(httpReq)
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"smm-helper/anomaly"
+	"smm-helper/history"
+	"smm-helper/notify"
+	"smm-helper/report"
+)
+
+// ========== ИСТОРИЯ МЕТРИК И АНОМАЛИИ ==========
+
+// Сколько последних постов снимаем при каждом обходе
+const historyPostsCount = 100
+
+// alertTarget — канал и адресат оповещения
+type alertTarget struct {
+	sender notify.Sender
+	to     int64
+}
+
+var alertTargets []alertTarget
+
+func initAlerts() {
+	ac := cfg.Alerts
+
+	var err error
+	if postHistory, err = history.NewStore(ac.HistoryFile); err != nil {
+		log.Fatal("Ошибка загрузки истории: ", err)
+	}
+	if alertLog, err = anomaly.LoadLog(ac.LogFile); err != nil {
+		log.Fatal("Ошибка загрузки алертов: ", err)
+	}
+	if ac.Method != anomaly.MethodZScore && ac.Method != anomaly.MethodIQR {
+		log.Fatalf("Неизвестный метод поиска аномалий: %q (zscore или iqr)", ac.Method)
+	}
+
+	if ac.Telegram.BotToken != "" {
+		tgSender := notify.NewTelegramSender(ac.Telegram.BotToken, ac.Telegram.APIURL)
+		for _, chat := range ac.TelegramChats {
+			alertTargets = append(alertTargets, alertTarget{sender: tgSender, to: chat})
+		}
+	}
+	if ac.VK.Token != "" {
+		vkSender := notify.NewVKSender(ac.VK.Token, ac.VK.APIURL)
+		for _, id := range ac.VKUserIDs {
+			alertTargets = append(alertTargets, alertTarget{sender: vkSender, to: id})
+		}
+	}
+}
+
+func alertSettings() anomaly.Settings {
+	return anomaly.Settings{
+		After:            time.Duration(cfg.Alerts.AfterHours) * time.Hour,
+		Method:           cfg.Alerts.Method,
+		Threshold:        cfg.Alerts.Threshold,
+		MinSamples:       cfg.Alerts.MinSamples,
+		ReachDropPercent: cfg.Alerts.ReachDropPercent,
+	}
+}
+
+func startAlerts() {
+	if !cfg.Alerts.Enabled {
+		return
+	}
+	interval := time.Duration(cfg.Alerts.SnapshotMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	fmt.Printf("📡 История метрик: снимок каждые %v, аномалии через %d ч (%s), каналов %d\n",
+		interval, cfg.Alerts.AfterHours, cfg.Alerts.Method, len(alertTargets))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := checkAnomalies(context.Background(), time.Now()); err != nil {
+				fmt.Println("❌ Аномалии:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// checkAnomalies снимает метрики последних постов, ищет аномалии и рассылает новые алерты
+func checkAnomalies(ctx context.Context, now time.Time) error {
+	posts, err := vkClient.GetWallPosts(groupID, historyPostsCount)
+	if err != nil {
+		return err
+	}
+
+	postHistory.Record(now, report.BuildPostStats(groupID, posts).Posts)
+	if err := postHistory.Save(); err != nil {
+		return err
+	}
+
+	settings := alertSettings()
+	snapshot := postHistory.Posts()
+	found := append(anomaly.DetectPosts(snapshot, settings, now), anomaly.DetectReachDrop(snapshot, settings, now)...)
+
+	fresh, err := alertLog.Add(found)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range fresh {
+		fmt.Println("⚠️ ", a.Message)
+		for _, t := range alertTargets {
+			if err := t.sender.Send(ctx, t.to, a.Message); err != nil {
+				fmt.Printf("❌ Алерт через %s → %d: %v\n", t.sender.Name(), t.to, err)
+			}
+		}
+	}
+	return nil
+}
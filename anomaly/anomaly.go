@@ -0,0 +1,327 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"smm-helper/history"
+	"smm-helper/storage"
+)
+
+const (
+	MethodZScore = "zscore"
+	MethodIQR    = "iqr"
+
+	KindViral     = "viral"      // пост сильно лучше обычного
+	KindFlop      = "flop"       // пост сильно хуже обычного
+	KindReachDrop = "reach_drop" // резкое падение просмотров за день
+)
+
+// Settings — параметры детектора
+type Settings struct {
+	After      time.Duration // в какой момент после публикации сравниваем посты
+	Method     string        // zscore или iqr
+	Threshold  float64       // |z| для zscore или множитель k для iqr
+	MinSamples int           // меньше постов в истории — не судим
+	// ReachDropPercent — на сколько процентов просмотры за день должны упасть
+	// относительно медианы прошлых 7 дней
+	ReachDropPercent float64
+}
+
+type Alert struct {
+	ID       string    `json:"id"` // ключ для дедупликации
+	Kind     string    `json:"kind"`
+	Metric   string    `json:"metric"` // views, er или reach
+	PostID   int       `json:"post_id,omitempty"`
+	Link     string    `json:"link,omitempty"`
+	Day      string    `json:"day,omitempty"` // для reach_drop, ГГГГ-ММ-ДД
+	Value    float64   `json:"value"`
+	Baseline float64   `json:"baseline"`
+	Score    float64   `json:"score"`
+	At       time.Time `json:"at"`
+	Message  string    `json:"message"`
+}
+
+// Окно, в котором снимок ещё считается снятым «через After часов»
+const snapshotWindow = 2 * time.Hour
+
+// DetectPosts сравнивает просмотры и ER каждого поста через After часов с остальными постами истории
+func DetectPosts(posts []history.Post, s Settings, now time.Time) []Alert {
+	type sample struct {
+		post  history.Post
+		views float64
+		er    float64
+	}
+
+	samples := []sample{}
+	for _, p := range posts {
+		if snap, ok := p.At(s.After, snapshotWindow); ok {
+			samples = append(samples, sample{post: p, views: float64(snap.Views), er: snap.ER()})
+		}
+	}
+	if len(samples) < s.MinSamples+1 {
+		return nil
+	}
+
+	alerts := []Alert{}
+	for i, current := range samples {
+		// Судим только свежие посты: старые уже проверены, а их оценка сдвигается вместе с историей
+		if now.Sub(current.post.Date) > s.After+24*time.Hour {
+			continue
+		}
+
+		views, ers := []float64{}, []float64{}
+		for j, other := range samples {
+			if i != j {
+				views = append(views, other.views)
+				ers = append(ers, other.er)
+			}
+		}
+
+		hours := int(s.After.Hours())
+		if a, ok := check(s, "views", current.views, views); ok {
+			a.Message = fmt.Sprintf("%s: %.0f просмотров через %d ч, обычно %.0f", kindLabel(a.Kind), a.Value, hours, a.Baseline)
+			alerts = append(alerts, postAlert(a, current.post, now))
+		}
+		if a, ok := check(s, "er", current.er, ers); ok {
+			a.Message = fmt.Sprintf("%s: ER %.2f%% через %d ч, обычно %.2f%%", kindLabel(a.Kind), a.Value, hours, a.Baseline)
+			alerts = append(alerts, postAlert(a, current.post, now))
+		}
+	}
+	return alerts
+}
+
+func postAlert(a Alert, p history.Post, now time.Time) Alert {
+	a.ID = fmt.Sprintf("%s:%s:%d", a.Kind, a.Metric, p.ID)
+	a.PostID = p.ID
+	a.Link = p.Link
+	a.At = now
+	a.Message = fmt.Sprintf("Пост от %s — %s\n%s", p.Date.Format("02.01 15:04"), a.Message, p.Link)
+	return a
+}
+
+// check — выбивается ли value из baseline выбранным методом
+func check(s Settings, metric string, value float64, baseline []float64) (Alert, bool) {
+	a := Alert{Metric: metric, Value: round(value)}
+
+	switch s.Method {
+	case MethodIQR:
+		q1, median, q3 := quartiles(baseline)
+		iqr := q3 - q1
+		a.Baseline = round(median)
+		switch {
+		case value > q3+s.Threshold*iqr:
+			a.Kind = KindViral
+		case value < q1-s.Threshold*iqr:
+			a.Kind = KindFlop
+		default:
+			return a, false
+		}
+		if iqr > 0 {
+			a.Score = round((value - median) / iqr)
+		}
+	default:
+		mean, std := meanStd(baseline)
+		a.Baseline = round(mean)
+		if std == 0 {
+			return a, false
+		}
+		z := (value - mean) / std
+		a.Score = round(z)
+		switch {
+		case z >= s.Threshold:
+			a.Kind = KindViral
+		case z <= -s.Threshold:
+			a.Kind = KindFlop
+		default:
+			return a, false
+		}
+	}
+	return a, true
+}
+
+// DailyReach — прирост просмотров всех постов за каждый день по снимкам истории.
+// Пост учитывается в дне, только если известны его просмотры на конец прошлого дня
+// (или он вышел в этот день) — иначе первый снимок старого поста дал бы ложный всплеск
+func DailyReach(posts []history.Post, from, to time.Time) map[string]int {
+	reach := map[string]int{}
+	for day := truncateDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		total := 0
+		for _, p := range posts {
+			if !p.Date.Before(end) {
+				continue
+			}
+			current, ok := viewsBefore(p, end)
+			if !ok {
+				continue
+			}
+			previous := 0
+			if p.Date.Before(day) {
+				if previous, ok = viewsBefore(p, day); !ok {
+					continue
+				}
+			}
+			total += current - previous
+		}
+		reach[day.Format("2006-01-02")] = total
+	}
+	return reach
+}
+
+// DetectReachDrop сравнивает просмотры за вчера с медианой семи дней до него
+func DetectReachDrop(posts []history.Post, s Settings, now time.Time) []Alert {
+	yesterday := truncateDay(now).AddDate(0, 0, -1)
+	reach := DailyReach(posts, yesterday.AddDate(0, 0, -7), yesterday)
+
+	baseline := []float64{}
+	for day := yesterday.AddDate(0, 0, -7); day.Before(yesterday); day = day.AddDate(0, 0, 1) {
+		if v := reach[day.Format("2006-01-02")]; v > 0 {
+			baseline = append(baseline, float64(v))
+		}
+	}
+	if len(baseline) < 3 {
+		return nil
+	}
+
+	_, median, _ := quartiles(baseline)
+	key := yesterday.Format("2006-01-02")
+	value := float64(reach[key])
+	if median == 0 || value > median*(1-s.ReachDropPercent/100) {
+		return nil
+	}
+
+	drop := (1 - value/median) * 100
+	return []Alert{{
+		ID:       KindReachDrop + ":" + key,
+		Kind:     KindReachDrop,
+		Metric:   "reach",
+		Day:      key,
+		Value:    value,
+		Baseline: round(median),
+		Score:    round(-drop),
+		At:       now,
+		Message:  fmt.Sprintf("📉 Просмотры за %s: %.0f — на %.0f%% ниже обычного (%.0f)", yesterday.Format("02.01"), value, drop, median),
+	}}
+}
+
+func viewsBefore(p history.Post, end time.Time) (int, bool) {
+	views, found := 0, false
+	for _, s := range p.Snapshots {
+		if !s.At.Before(end) {
+			break
+		}
+		views, found = s.Views, true
+	}
+	return views, found
+}
+
+func kindLabel(kind string) string {
+	if kind == KindViral {
+		return "🚀 Выше обычного"
+	}
+	return "🔻 Ниже обычного"
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// quartiles — Q1, медиана и Q3 с линейной интерполяцией
+func quartiles(values []float64) (float64, float64, float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 0.25), percentile(sorted, 0.5), percentile(sorted, 0.75)
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Log — журнал отправленных алертов, чтобы не повторять их и показывать на дашборде
+type Log struct {
+	path   string
+	alerts []Alert
+	mu     sync.RWMutex
+}
+
+// Сколько последних алертов храним
+const logSize = 200
+
+func LoadLog(path string) (*Log, error) {
+	l := &Log{path: path}
+	if err := storage.ReadJSON(path, &l.alerts); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	return l, nil
+}
+
+// Add добавляет новые алерты и возвращает только те, которых ещё не было
+func (l *Log) Add(alerts []Alert) ([]Alert, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, a := range l.alerts {
+		seen[a.ID] = true
+	}
+
+	fresh := []Alert{}
+	for _, a := range alerts {
+		if !seen[a.ID] {
+			seen[a.ID] = true
+			fresh = append(fresh, a)
+		}
+	}
+	if len(fresh) == 0 {
+		return fresh, nil
+	}
+
+	l.alerts = append(l.alerts, fresh...)
+	if len(l.alerts) > logSize {
+		l.alerts = l.alerts[len(l.alerts)-logSize:]
+	}
+	return fresh, storage.WriteJSON(l.path, l.alerts)
+}
+
+// Recent — последние n алертов, новые первыми
+func (l *Log) Recent(n int) []Alert {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	recent := []Alert{}
+	for i := len(l.alerts) - 1; i >= 0 && len(recent) < n; i-- {
+		recent = append(recent, l.alerts[i])
+	}
+	return recent
+}
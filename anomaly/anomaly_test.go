@@ -0,0 +1,214 @@
+package anomaly
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"smm-helper/history"
+)
+
+// Понедельник 4 марта 2024, полдень
+var now = time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+
+// histPost — пост со снимком через 24 часа после публикации. ER поста — 10%
+func histPost(id int, age time.Duration, views int) history.Post {
+	date := now.Add(-age)
+	return history.Post{
+		ID:        id,
+		Date:      date,
+		Link:      fmt.Sprintf("https://vk.com/wall-1_%d", id),
+		Snapshots: []history.Snapshot{{At: date.Add(24 * time.Hour), Views: views, Likes: views / 10}},
+	}
+}
+
+// Пять старых постов вокруг 100 просмотров и один свежий с заданными просмотрами.
+// У старых среднее 100, отклонение √200, Q1 90, Q3 110
+func withFresh(views int) []history.Post {
+	posts := []history.Post{}
+	for i, v := range []int{100, 110, 90, 120, 80} {
+		posts = append(posts, histPost(i+1, time.Duration(10-i)*24*time.Hour, v))
+	}
+	return append(posts, histPost(99, 25*time.Hour, views))
+}
+
+func TestDetectPosts(t *testing.T) {
+	zscore := Settings{After: 24 * time.Hour, Method: MethodZScore, Threshold: 2, MinSamples: 5}
+	iqr := Settings{After: 24 * time.Hour, Method: MethodIQR, Threshold: 1.5, MinSamples: 5}
+
+	flat := []history.Post{}
+	for i := 1; i <= 5; i++ {
+		flat = append(flat, histPost(i, time.Duration(10-i)*24*time.Hour, 100))
+	}
+	flat = append(flat, histPost(99, 25*time.Hour, 500))
+
+	// Выброс среди старых постов уже проверен и повторно не судится
+	oldOutlier := withFresh(100)
+	oldOutlier[0].Snapshots[0].Views = 5000
+
+	// Снимок сделан позже окна: через 30 часов вместо 24
+	late := withFresh(400)
+	late[5].Snapshots[0].At = late[5].Date.Add(30 * time.Hour)
+
+	tests := []struct {
+		name     string
+		settings Settings
+		posts    []history.Post
+		kinds    []string // Kind:Metric ожидаемых алертов
+	}{
+		{"zscore: вирусный пост", zscore, withFresh(400), []string{"viral:views"}},
+		{"zscore: провал", zscore, withFresh(10), []string{"flop:views"}},
+		{"zscore: обычный пост", zscore, withFresh(102), nil},
+		{"zscore: нулевой разброс", zscore, flat, nil},
+		{"iqr: вирусный пост", iqr, withFresh(150), []string{"viral:views"}},
+		{"iqr: провал", iqr, withFresh(50), []string{"flop:views"}},
+		{"iqr: в пределах усов", iqr, withFresh(130), nil},
+		{"пустая история", zscore, nil, nil},
+		{"мало постов в истории", zscore, withFresh(400)[1:], nil},
+		{"старые посты не судятся", zscore, oldOutlier, nil},
+		{"снимок вне окна", zscore, late, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := DetectPosts(tt.posts, tt.settings, now)
+			if len(alerts) != len(tt.kinds) {
+				t.Fatalf("алертов %d, ожидалось %d: %+v", len(alerts), len(tt.kinds), alerts)
+			}
+			for i, a := range alerts {
+				if got := a.Kind + ":" + a.Metric; got != tt.kinds[i] {
+					t.Errorf("алерт %d: %s, ожидался %s", i, got, tt.kinds[i])
+				}
+				if a.PostID != 99 || a.ID != a.Kind+":"+a.Metric+":99" || !a.At.Equal(now) {
+					t.Errorf("алерт %+v", a)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectPostsScore(t *testing.T) {
+	alerts := DetectPosts(withFresh(400), Settings{After: 24 * time.Hour, Method: MethodZScore, Threshold: 2, MinSamples: 5}, now)
+	if len(alerts) != 1 {
+		t.Fatalf("алертов %d", len(alerts))
+	}
+	// (400 − 100) / √200
+	if a := alerts[0]; a.Value != 400 || a.Baseline != 100 || a.Score != 21.21 {
+		t.Errorf("Value %.2f, Baseline %.2f, Score %.2f", a.Value, a.Baseline, a.Score)
+	}
+}
+
+func day(offset int) time.Time {
+	return time.Date(2024, 3, 4+offset, 0, 0, 0, 0, time.Local)
+}
+
+func TestDailyReach(t *testing.T) {
+	posts := []history.Post{
+		// Вышел в первый день периода
+		{ID: 1, Date: day(0).Add(10 * time.Hour), Snapshots: []history.Snapshot{
+			{At: day(0).Add(12 * time.Hour), Views: 100},
+			{At: day(1).Add(12 * time.Hour), Views: 150},
+			{At: day(2).Add(12 * time.Hour), Views: 170},
+		}},
+		// Старый пост: первый снимок только на второй день, до этого прирост неизвестен
+		{ID: 2, Date: day(-30), Snapshots: []history.Snapshot{
+			{At: day(1).Add(12 * time.Hour), Views: 1000},
+			{At: day(2).Add(12 * time.Hour), Views: 1100},
+		}},
+		// Вышел после периода
+		{ID: 3, Date: day(5), Snapshots: []history.Snapshot{{At: day(5).Add(time.Hour), Views: 50}}},
+	}
+
+	tests := []struct {
+		name  string
+		posts []history.Post
+		want  map[string]int
+	}{
+		{"посты", posts, map[string]int{"2024-03-04": 100, "2024-03-05": 50, "2024-03-06": 120}},
+		{"пустая история", nil, map[string]int{"2024-03-04": 0, "2024-03-05": 0, "2024-03-06": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DailyReach(tt.posts, day(0).Add(9*time.Hour), day(2).Add(23*time.Hour))
+			if len(got) != len(tt.want) {
+				t.Errorf("дней %d, ожидалось %d: %v", len(got), len(tt.want), got)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s: %d, ожидалось %d", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+// reachPost — один пост с накопленными просмотрами на конец каждого дня:
+// за 7 дней до вчера по daily просмотров в день, вчера — yesterday
+func reachPost(daily, yesterday int) []history.Post {
+	p := history.Post{ID: 1, Date: day(-30)}
+	views := 1000
+	p.Snapshots = append(p.Snapshots, history.Snapshot{At: day(-9).Add(23 * time.Hour), Views: views})
+	for d := -8; d <= -2; d++ {
+		views += daily
+		p.Snapshots = append(p.Snapshots, history.Snapshot{At: day(d).Add(23 * time.Hour), Views: views})
+	}
+	views += yesterday
+	p.Snapshots = append(p.Snapshots, history.Snapshot{At: day(-1).Add(23 * time.Hour), Views: views})
+	return []history.Post{p}
+}
+
+func TestDetectReachDrop(t *testing.T) {
+	s := Settings{ReachDropPercent: 50}
+	at := day(0).Add(9 * time.Hour)
+
+	tests := []struct {
+		name  string
+		posts []history.Post
+		drop  bool
+	}{
+		{"падение на 70%", reachPost(100, 30), true},
+		{"ровно на порог", reachPost(100, 50), true},
+		{"падение на 20%", reachPost(100, 80), false},
+		{"рост", reachPost(100, 300), false},
+		{"нет просмотров в прошлые дни", reachPost(0, 0), false},
+		{"пустая история", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := DetectReachDrop(tt.posts, s, at)
+			if (len(alerts) == 1) != tt.drop || len(alerts) > 1 {
+				t.Fatalf("алерты %+v, ожидалось падение: %v", alerts, tt.drop)
+			}
+		})
+	}
+
+	alerts := DetectReachDrop(reachPost(100, 30), s, at)
+	if a := alerts[0]; a.ID != "reach_drop:2024-03-03" || a.Value != 30 || a.Baseline != 100 || a.Score != -70 {
+		t.Errorf("алерт %+v", a)
+	}
+}
+
+func TestLogAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	l, err := LoadLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err := l.Add([]Alert{{ID: "viral:views:1"}, {ID: "flop:er:2"}, {ID: "viral:views:1"}})
+	if err != nil || len(fresh) != 2 {
+		t.Fatalf("новых %d, ошибка %v", len(fresh), err)
+	}
+
+	// После перезапуска те же алерты не повторяются
+	reloaded, err := LoadLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh, _ := reloaded.Add([]Alert{{ID: "flop:er:2"}, {ID: "reach_drop:2024-03-03"}}); len(fresh) != 1 || fresh[0].ID != "reach_drop:2024-03-03" {
+		t.Errorf("новые после перезапуска %+v", fresh)
+	}
+	if recent := reloaded.Recent(2); len(recent) != 2 || recent[0].ID != "reach_drop:2024-03-03" {
+		t.Errorf("Recent(2) = %+v", recent)
+	}
+}
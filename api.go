@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"smm-helper/auth"
+	"smm-helper/kpi"
+	"smm-helper/post"
+	"smm-helper/reminder"
+	"smm-helper/report"
+
+	"github.com/gorilla/mux"
+)
+
+// ========== JSON API v1 ==========
+
+type apiGroup struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	URL    string `json:"url"`
+}
+
+type apiCacheStatus struct {
+	Items int `json:"items"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Ответы всегда в конверте: {"data": ...} или {"error": {...}}
+type apiEnvelope struct {
+	Data  interface{} `json:"data,omitempty"`
+	Error *apiError   `json:"error,omitempty"`
+}
+
+func registerAPIRoutes(r *mux.Router) {
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "api/openapi.json")
+	}).Methods("GET")
+
+	everyone := api.NewRoute().Subrouter()
+	everyone.Use(authManager.Require(auth.RoleAdmin, auth.RoleManager, auth.RoleEmployee))
+	everyone.HandleFunc("/employees/activity", apiEmployeeActivityHandler).Methods("GET")
+	everyone.HandleFunc("/employees/kpi", apiEmployeeKPIHandler).Methods("GET")
+
+	reports := api.NewRoute().Subrouter()
+	reports.Use(authManager.Require(auth.RoleAdmin, auth.RoleManager))
+	reports.HandleFunc("/groups", apiGroupsHandler).Methods("GET")
+	reports.HandleFunc("/posts", apiPostsHandler).Methods("GET")
+	reports.HandleFunc("/posts/stats", apiPostStatsHandler).Methods("GET")
+	reports.HandleFunc("/analysis", apiAnalysisHandler).Methods("GET")
+	reports.HandleFunc("/reports/date_range", apiDateRangeHandler).Methods("GET")
+	reports.HandleFunc("/reports/digest", apiDigestHandler).Methods("GET")
+	reports.HandleFunc("/alerts", apiAlertsHandler).Methods("GET")
+
+	admin := api.NewRoute().Subrouter()
+	admin.Use(authManager.Require(auth.RoleAdmin))
+	admin.HandleFunc("/cache", apiCacheStatusHandler).Methods("GET")
+	admin.HandleFunc("/cache", apiClearCacheHandler).Methods("DELETE")
+	admin.HandleFunc("/reminders", apiRemindersPreviewHandler).Methods("GET")
+	admin.HandleFunc("/reminders/run", apiRemindersRunHandler).Methods("POST")
+	admin.HandleFunc("/digests/{name}/send", apiSendDigestHandler).Methods("POST")
+
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "not_found", "метод API не найден")
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiEnvelope{Data: data})
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiEnvelope{Error: &apiError{Code: code, Message: message}})
+}
+
+// queryCount читает параметр count (1..100), по умолчанию 30
+func queryCount(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("count")
+	if value == "" {
+		return 30, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 || count > 100 {
+		return 0, fmt.Errorf("count должен быть числом от 1 до 100")
+	}
+	return count, nil
+}
+
+func apiGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, []apiGroup{{
+		ID:     -groupID,
+		Name:   groupName,
+		Domain: GROUP_DOMAIN,
+		URL:    "https://vk.com/" + GROUP_DOMAIN,
+	}})
+}
+
+func apiPostsHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryCount(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", "offset не может быть отрицательным")
+		return
+	}
+
+	posts, err := vkClient.GetWallPostsWithOffset(groupID, count, offset)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report.BuildPostStats(groupID, posts).Posts)
+}
+
+func apiPostStatsHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryCount(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	cacheKey := fmt.Sprintf("api_posts_stats_%d", count)
+	if cached, found := dataCache.Get(cacheKey); found {
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	posts, err := vkClient.GetWallPosts(groupID, count)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+		return
+	}
+
+	result := report.BuildPostStats(groupID, posts)
+	dataCache.Set(cacheKey, result, 30*time.Minute)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// apiAnalysisHandler — итоги по постам любой площадки одним кодом: посты сначала
+// переводятся в общий вид, затем считаются report.Analyze
+func apiAnalysisHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryCount(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	platform := post.Platform(r.URL.Query().Get("platform"))
+	if platform == "" {
+		platform = post.VK
+	}
+
+	cacheKey := fmt.Sprintf("api_analysis_%s_%d", platform, count)
+	if cached, found := dataCache.Get(cacheKey); found {
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	var posts []post.Post
+	switch platform {
+	case post.VK:
+		vkPosts, err := vkClient.GetWallPosts(groupID, count)
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+			return
+		}
+		posts = post.FromVKPosts(groupID, vkPosts)
+	case post.Telegram:
+		tgPosts, _, err := getTGPosts(count)
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, "telegram_error", err.Error())
+			return
+		}
+		posts = post.FromTGPosts(cfg.Telegram.ChannelID, tgPosts)
+	default:
+		writeAPIError(w, http.StatusBadRequest, "bad_request", "platform должен быть vk или telegram")
+		return
+	}
+
+	result := report.Analyze(posts)
+	dataCache.Set(cacheKey, result, 30*time.Minute)
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiEmployeeActivityHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryCount(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	result, err := getEmployeeActivity(count)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+		return
+	}
+
+	if session, _ := auth.FromContext(r.Context()); session.Role == auth.RoleEmployee {
+		result = result.Only(session.VKID)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiEmployeeKPIHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryCount(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	activity, err := getEmployeeActivity(count)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+		return
+	}
+
+	if session, _ := auth.FromContext(r.Context()); session.Role == auth.RoleEmployee {
+		activity = activity.Only(session.VKID)
+	}
+	writeJSON(w, http.StatusOK, kpi.Evaluate(activity, kpiStore.Rules(), employeeRole))
+}
+
+func apiDateRangeHandler(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	startDate, err1 := time.ParseInLocation("2006-01-02", from, time.Local)
+	endDate, err2 := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err1 != nil || err2 != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", "неверный формат даты (ГГГГ-ММ-ДД)")
+		return
+	}
+	if endDate.Before(startDate) {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", "дата окончания раньше даты начала")
+		return
+	}
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute)
+
+	result := report.BuildRangeReport(groupID, startDate, endDate, getPostsInRange(startDate, endDate))
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiDigestHandler(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = report.PeriodWeek
+	}
+
+	result, err := buildDigest(period, time.Now())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryCount(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, alertLog.Recent(count))
+}
+
+// apiSendDigestHandler — отправить рассылку сейчас, не дожидаясь расписания
+func apiSendDigestHandler(w http.ResponseWriter, r *http.Request) {
+	job, found := digests.Job(mux.Vars(r)["name"])
+	if !found {
+		writeAPIError(w, http.StatusNotFound, "not_found", "рассылка не найдена")
+		return
+	}
+
+	if err := digests.Send(r.Context(), job, time.Now()); err != nil {
+		writeAPIError(w, http.StatusBadGateway, "delivery_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"sent": job.Name})
+}
+
+// apiRemindersPreviewHandler — какие напоминания ушли бы сейчас (без учёта тихих часов)
+func apiRemindersPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	reminders, err := reminderBot.Plan(time.Now())
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, reminders)
+}
+
+// apiRemindersRunHandler — внеочередная проверка с отправкой
+func apiRemindersRunHandler(w http.ResponseWriter, r *http.Request) {
+	sent, err := reminderBot.Check(r.Context(), time.Now())
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "vk_error", err.Error())
+		return
+	}
+	if sent == nil {
+		sent = []reminder.Reminder{}
+	}
+	writeJSON(w, http.StatusOK, sent)
+}
+
+func apiCacheStatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, apiCacheStatus{Items: dataCache.Len()})
+}
+
+func apiClearCacheHandler(w http.ResponseWriter, r *http.Request) {
+	dataCache.Clear()
+	vkWall.Reset()
+	tgPublic.ClearCache()
+	fmt.Println("🗑️ Кэш очищен (API)")
+	writeJSON(w, http.StatusOK, apiCacheStatus{Items: 0})
+}
@@ -0,0 +1,40 @@
+package auth
+
+import "testing"
+
+func TestLinkSigner(t *testing.T) {
+	signer := NewLinkSigner("secret")
+	sig := signer.Sign(10)
+
+	if len(sig) != 32 {
+		t.Errorf("длина подписи %d, ожидалось 32", len(sig))
+	}
+	if sig != signer.Sign(10) {
+		t.Error("подпись одного сотрудника меняется")
+	}
+
+	tampered := []byte(sig)
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name      string
+		signer    *LinkSigner
+		id        int
+		signature string
+		ok        bool
+	}{
+		{"своя подпись", signer, 10, sig, true},
+		{"чужой сотрудник", signer, 11, sig, false},
+		{"изменённая подпись", signer, 10, string(tampered), false},
+		{"обрезанная подпись", signer, 10, sig[:16], false},
+		{"пустая подпись", signer, 10, "", false},
+		{"другой секрет", NewLinkSigner("other"), 10, sig, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.signer.Verify(tt.id, tt.signature); got != tt.ok {
+				t.Errorf("Verify(%d, %q) = %v, ожидалось %v", tt.id, tt.signature, got, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type contextKey struct{}
+
+// Manager связывает пользователей, сессии и внешний вход
+type Manager struct {
+	Users    *UserStore
+	Sessions *SessionStore
+	Provider OAuthProvider // nil — вход через VK ID выключен
+
+	// OnDenied вызывается, когда доступ запрещён: 401 — нет сессии, 403 — не та роль.
+	// По умолчанию 401 уводит на /login, 403 отдаёт текст ошибки
+	OnDenied func(w http.ResponseWriter, r *http.Request, status int)
+}
+
+func NewManager(users *UserStore, sessions *SessionStore) *Manager {
+	return &Manager{Users: users, Sessions: sessions}
+}
+
+// Middleware кладёт сессию из cookie в контекст запроса. Подходит для mux.Router.Use
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(SessionCookie); err == nil {
+			if session, found := m.Sessions.Get(cookie.Value); found {
+				r = r.WithContext(context.WithValue(r.Context(), contextKey{}, session))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require пропускает только сессии с одной из ролей
+func (m *Manager) Require(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, found := FromContext(r.Context())
+			if !found {
+				m.deny(w, r, http.StatusUnauthorized)
+				return
+			}
+			for _, role := range roles {
+				if session.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			m.deny(w, r, http.StatusForbidden)
+		})
+	}
+}
+
+func (m *Manager) deny(w http.ResponseWriter, r *http.Request, status int) {
+	if m.OnDenied != nil {
+		m.OnDenied(w, r, status)
+		return
+	}
+	DefaultDeny(w, r, status)
+}
+
+// DefaultDeny — реакция на отказ для HTML страниц
+func DefaultDeny(w http.ResponseWriter, r *http.Request, status int) {
+	if status == http.StatusUnauthorized {
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+		return
+	}
+	http.Error(w, "Недостаточно прав", http.StatusForbidden)
+}
+
+// Login создаёт сессию и ставит cookie
+func (m *Manager) Login(w http.ResponseWriter, r *http.Request, u *User) {
+	session := m.Sessions.Create(u)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.Expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (m *Manager) Logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		m.Sessions.Delete(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: "", Path: "/", MaxAge: -1})
+}
+
+func FromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(contextKey{}).(*Session)
+	return session, ok
+}
+
+func (s *Session) IsAdmin() bool {
+	return s.Role == RoleAdmin
+}
+
+// CanViewReports — доступ ко всем отчётам (админ и менеджер)
+func (s *Session) CanViewReports() bool {
+	return s.Role == RoleAdmin || s.Role == RoleManager
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// serve пропускает запрос через Middleware и Require(roles...) и возвращает ответ
+func serve(m *Manager, token string, roles ...string) *httptest.ResponseRecorder {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	handler := m.Middleware(m.Require(roles...)(ok))
+
+	r := httptest.NewRequest("GET", "/employee?id=1", nil)
+	if token != "" {
+		r.AddCookie(&http.Cookie{Name: SessionCookie, Value: token})
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRequire(t *testing.T) {
+	m := NewManager(nil, NewSessionStore(time.Hour))
+	tokens := map[string]string{}
+	for _, role := range []string{RoleAdmin, RoleManager, RoleEmployee} {
+		tokens[role] = m.Sessions.Create(&User{Username: role, Role: role}).Token
+	}
+
+	// Наборы ролей — как у маршрутов в main.go
+	adminOnly := []string{RoleAdmin}
+	reports := []string{RoleAdmin, RoleManager}
+	everyone := []string{RoleAdmin, RoleManager, RoleEmployee}
+
+	tests := []struct {
+		name   string
+		role   string
+		roles  []string
+		status int
+	}{
+		{"админ — настройки", RoleAdmin, adminOnly, http.StatusOK},
+		{"менеджер — настройки", RoleManager, adminOnly, http.StatusForbidden},
+		{"сотрудник — настройки", RoleEmployee, adminOnly, http.StatusForbidden},
+		{"админ — отчёты", RoleAdmin, reports, http.StatusOK},
+		{"менеджер — отчёты", RoleManager, reports, http.StatusOK},
+		{"сотрудник — отчёты", RoleEmployee, reports, http.StatusForbidden},
+		{"админ — своя активность", RoleAdmin, everyone, http.StatusOK},
+		{"менеджер — своя активность", RoleManager, everyone, http.StatusOK},
+		{"сотрудник — своя активность", RoleEmployee, everyone, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(m, tokens[tt.role], tt.roles...); w.Code != tt.status {
+				t.Errorf("код %d, ожидался %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestRequireWithoutSession(t *testing.T) {
+	m := NewManager(nil, NewSessionStore(time.Hour))
+	expired := NewSessionStore(-time.Second).Create(&User{Username: "anna", Role: RoleAdmin})
+
+	for name, token := range map[string]string{"без cookie": "", "чужой токен": "unknown", "просроченная сессия": expired.Token} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(m, token, RoleAdmin)
+			if w.Code != http.StatusSeeOther {
+				t.Fatalf("код %d, ожидалось перенаправление на вход", w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != "/login?next=%2Femployee%3Fid%3D1" {
+				t.Errorf("Location = %q", loc)
+			}
+		})
+	}
+}
+
+func TestRequireOnDenied(t *testing.T) {
+	m := NewManager(nil, NewSessionStore(time.Hour))
+	var denied []int
+	m.OnDenied = func(w http.ResponseWriter, r *http.Request, status int) {
+		denied = append(denied, status)
+		w.WriteHeader(status)
+	}
+	employee := m.Sessions.Create(&User{Username: "boris", Role: RoleEmployee})
+
+	serve(m, "", RoleAdmin)
+	serve(m, employee.Token, RoleAdmin)
+	if len(denied) != 2 || denied[0] != http.StatusUnauthorized || denied[1] != http.StatusForbidden {
+		t.Errorf("OnDenied вызван с %v, ожидалось [401 403]", denied)
+	}
+}
+
+func TestLoginLogout(t *testing.T) {
+	m := NewManager(nil, NewSessionStore(time.Hour))
+
+	w := httptest.NewRecorder()
+	m.Login(w, httptest.NewRequest("POST", "/login", nil), &User{Username: "anna", Role: RoleManager})
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != SessionCookie || !cookies[0].HttpOnly {
+		t.Fatalf("cookie %+v", cookies)
+	}
+	token := cookies[0].Value
+	if w := serve(m, token, RoleManager); w.Code != http.StatusOK {
+		t.Fatalf("после входа код %d", w.Code)
+	}
+
+	r := httptest.NewRequest("POST", "/logout", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookie, Value: token})
+	m.Logout(httptest.NewRecorder(), r)
+	if w := serve(m, token, RoleManager); w.Code != http.StatusSeeOther {
+		t.Errorf("после выхода код %d, ожидалось перенаправление на вход", w.Code)
+	}
+}
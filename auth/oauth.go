@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuthProvider — внешний вход. Интерфейс позволяет подменить VK ID в тестах и при отладке
+type OAuthProvider interface {
+	AuthURL(state string) string
+	// Exchange меняет code на ID пользователя VK
+	Exchange(ctx context.Context, code string) (int, error)
+}
+
+type VKIDProvider struct {
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	AuthEndpoint  string
+	TokenEndpoint string
+	httpClient    *http.Client
+}
+
+func NewVKIDProvider(clientID, clientSecret, redirectURL string) *VKIDProvider {
+	return &VKIDProvider{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   redirectURL,
+		AuthEndpoint:  "https://oauth.vk.com/authorize",
+		TokenEndpoint: "https://oauth.vk.com/access_token",
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *VKIDProvider) AuthURL(state string) string {
+	params := url.Values{}
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("response_type", "code")
+	params.Set("display", "page")
+	params.Set("state", state)
+	return p.AuthEndpoint + "?" + params.Encode()
+}
+
+func (p *VKIDProvider) Exchange(ctx context.Context, code string) (int, error) {
+	params := url.Values{}
+	params.Set("client_id", p.ClientID)
+	params.Set("client_secret", p.ClientSecret)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("code", code)
+
+	// client_secret передаём в теле запроса: адрес попадает в текст ошибок и логи
+	req, err := http.NewRequestWithContext(ctx, "POST", p.TokenEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return 0, fmt.Errorf("VK ID: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("VK ID: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		UserID           int    `json:"user_id"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result.Error != "" {
+		return 0, fmt.Errorf("VK ID: %s", result.ErrorDescription)
+	}
+	if result.UserID == 0 {
+		return 0, fmt.Errorf("VK ID не вернул user_id")
+	}
+	return result.UserID, nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProvider — вход через VK ID без обращения к VK
+type fakeProvider struct {
+	users map[string]int // code → ID пользователя VK
+}
+
+func (p fakeProvider) AuthURL(state string) string {
+	return "/fake/authorize?state=" + state
+}
+
+func (p fakeProvider) Exchange(ctx context.Context, code string) (int, error) {
+	if id, found := p.users[code]; found {
+		return id, nil
+	}
+	return 0, fmt.Errorf("неизвестный code")
+}
+
+// Путь входа через внешнего провайдера: code → ID VK → пользователь → сессия с его ролью
+func TestManagerWithFakeProvider(t *testing.T) {
+	users, _ := newTestUsers(t)
+	m := NewManager(users, NewSessionStore(time.Hour))
+	m.Provider = fakeProvider{users: map[string]int{"good": 42, "stranger": 99}}
+
+	login := func(code string) (*User, error) {
+		vkID, err := m.Provider.Exchange(context.Background(), code)
+		if err != nil {
+			return nil, err
+		}
+		user, found := m.Users.FindByVKID(vkID)
+		if !found {
+			return nil, fmt.Errorf("аккаунт VK %d не привязан", vkID)
+		}
+		return user, nil
+	}
+
+	if _, err := login("bad"); err == nil {
+		t.Error("вход по неверному code")
+	}
+	if _, err := login("stranger"); err == nil {
+		t.Error("вход с непривязанным аккаунтом VK")
+	}
+
+	user, err := login("good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	m.Login(w, httptest.NewRequest("GET", "/auth/vk/callback", nil), user)
+	token := w.Result().Cookies()[0].Value
+	if w := serve(m, token, RoleAdmin, RoleManager); w.Code != http.StatusOK {
+		t.Errorf("менеджер, вошедший через VK, получил код %d", w.Code)
+	}
+	if w := serve(m, token, RoleAdmin); w.Code != http.StatusForbidden {
+		t.Errorf("менеджеру открыты настройки: код %d", w.Code)
+	}
+}
+
+func TestVKIDProviderAuthURL(t *testing.T) {
+	p := NewVKIDProvider("123", "secret", "https://smm.example.com/auth/vk/callback")
+	u, err := url.Parse(p.AuthURL("state-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != "123" || q.Get("state") != "state-1" || q.Get("response_type") != "code" ||
+		q.Get("redirect_uri") != "https://smm.example.com/auth/vk/callback" {
+		t.Errorf("AuthURL = %s", u)
+	}
+	if q.Has("client_secret") {
+		t.Error("client_secret в адресе авторизации")
+	}
+}
+
+func TestVKIDProviderExchange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.RawQuery != "" {
+			t.Errorf("запрос %s ?%s, параметры должны идти в теле POST", r.Method, r.URL.RawQuery)
+		}
+		if r.FormValue("client_id") != "123" || r.FormValue("client_secret") != "secret" {
+			t.Errorf("client_id=%q client_secret=%q", r.FormValue("client_id"), r.FormValue("client_secret"))
+		}
+		switch r.FormValue("code") {
+		case "good":
+			w.Write([]byte(`{"access_token":"t","user_id":42}`))
+		case "no-user":
+			w.Write([]byte(`{"access_token":"t"}`))
+		default:
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"Code is invalid or expired."}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewVKIDProvider("123", "secret", "https://smm.example.com/auth/vk/callback")
+	p.TokenEndpoint = srv.URL + "/access_token"
+
+	tests := []struct {
+		code string
+		id   int
+		err  string
+	}{
+		{"good", 42, ""},
+		{"no-user", 0, "не вернул user_id"},
+		{"expired", 0, "Code is invalid or expired."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			id, err := p.Exchange(context.Background(), tt.code)
+			if tt.err == "" {
+				if err != nil || id != tt.id {
+					t.Errorf("Exchange() = %d, %v", id, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ошибка %v, ожидалась %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// Ошибка сети попадает в лог — client_secret в ней быть не должно
+func TestVKIDProviderExchangeErrorHidesSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	p := NewVKIDProvider("123", "top-secret", "https://smm.example.com/auth/vk/callback")
+	p.TokenEndpoint = srv.URL + "/access_token"
+	_, err := p.Exchange(context.Background(), "good")
+	if err == nil {
+		t.Fatal("ожидалась ошибка соединения")
+	}
+	if strings.Contains(err.Error(), "top-secret") {
+		t.Errorf("client_secret в тексте ошибки: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const SessionCookie = "smm_session"
+
+type Session struct {
+	Token    string
+	Username string
+	Name     string
+	Role     string
+	VKID     int
+	Expires  time.Time
+}
+
+// SessionStore хранит сессии в памяти: после перезапуска сервера нужно войти заново
+type SessionStore struct {
+	sessions map[string]Session
+	ttl      time.Duration
+	mu       sync.RWMutex
+}
+
+func NewSessionStore(ttl time.Duration) *SessionStore {
+	return &SessionStore{sessions: make(map[string]Session), ttl: ttl}
+}
+
+func (s *SessionStore) Create(u *User) Session {
+	session := Session{
+		Token:    RandomToken(),
+		Username: u.Username,
+		Name:     u.Name,
+		Role:     u.Role,
+		VKID:     u.VKID,
+		Expires:  time.Now().Add(s.ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.Token] = session
+
+	// Заодно чистим просроченные
+	for token, old := range s.sessions {
+		if time.Now().After(old.Expires) {
+			delete(s.sessions, token)
+		}
+	}
+	return session
+}
+
+func (s *SessionStore) Get(token string) (*Session, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, found := s.sessions[token]
+	if !found || time.Now().After(session.Expires) {
+		return nil, false
+	}
+	return &session, true
+}
+
+func (s *SessionStore) Delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, token)
+}
+
+// DeleteUser завершает все сессии пользователя (после смены роли или удаления)
+func (s *SessionStore) DeleteUser(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, session := range s.sessions {
+		if session.Username == username {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+func RandomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
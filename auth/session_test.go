@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStore(t *testing.T) {
+	sessions := NewSessionStore(time.Hour)
+	anna := sessions.Create(&User{Username: "anna", Name: "Анна", Role: RoleManager, VKID: 42})
+
+	got, found := sessions.Get(anna.Token)
+	if !found || got.Username != "anna" || got.Role != RoleManager || got.VKID != 42 {
+		t.Fatalf("Get() = %+v, %v", got, found)
+	}
+	if _, found := sessions.Get("unknown"); found {
+		t.Error("найдена сессия по чужому токену")
+	}
+
+	sessions.Delete(anna.Token)
+	if _, found := sessions.Get(anna.Token); found {
+		t.Error("сессия осталась после Delete")
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	sessions := NewSessionStore(-time.Second)
+	expired := sessions.Create(&User{Username: "anna", Role: RoleAdmin})
+	if _, found := sessions.Get(expired.Token); found {
+		t.Error("просроченная сессия действует")
+	}
+
+	// Новая сессия вычищает просроченные из памяти
+	sessions.ttl = time.Hour
+	sessions.Create(&User{Username: "boris", Role: RoleEmployee})
+	if n := len(sessions.sessions); n != 1 {
+		t.Errorf("сессий в памяти %d, ожидалась 1", n)
+	}
+}
+
+func TestSessionDeleteUser(t *testing.T) {
+	sessions := NewSessionStore(time.Hour)
+	first := sessions.Create(&User{Username: "anna", Role: RoleManager})
+	second := sessions.Create(&User{Username: "anna", Role: RoleManager})
+	other := sessions.Create(&User{Username: "boris", Role: RoleEmployee})
+
+	sessions.DeleteUser("anna")
+	for _, token := range []string{first.Token, second.Token} {
+		if _, found := sessions.Get(token); found {
+			t.Error("сессия anna осталась после DeleteUser")
+		}
+	}
+	if _, found := sessions.Get(other.Token); !found {
+		t.Error("DeleteUser завершил чужую сессию")
+	}
+	if first.Token == second.Token || len(first.Token) != 64 {
+		t.Errorf("токены %q и %q", first.Token, second.Token)
+	}
+}
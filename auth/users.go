@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"smm-helper/storage"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	RoleAdmin    = "admin"    // настройки, кэш, состав сотрудников
+	RoleManager  = "manager"  // все отчёты
+	RoleEmployee = "employee" // только своя активность
+)
+
+var ErrInvalidCredentials = fmt.Errorf("неверный логин или пароль")
+
+type User struct {
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	PasswordHash string `json:"password_hash,omitempty"`
+	Role         string `json:"role"`
+	VKID         int    `json:"vk_id,omitempty"`
+}
+
+// UserStore — локальное хранилище пользователей в JSON файле
+type UserStore struct {
+	path  string
+	users map[string]User
+	mu    sync.RWMutex
+}
+
+func NewUserStore(path string) (*UserStore, error) {
+	s := &UserStore{path: path, users: make(map[string]User)}
+
+	var users []User
+	if err := storage.ReadJSON(path, &users); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	for _, u := range users {
+		s.users[u.Username] = u
+	}
+	return s, nil
+}
+
+func ValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleManager || role == RoleEmployee
+}
+
+func (s *UserStore) Authenticate(username, password string) (*User, error) {
+	s.mu.RLock()
+	u, found := s.users[username]
+	s.mu.RUnlock()
+
+	if !found || u.PasswordHash == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &u, nil
+}
+
+// FindByVKID ищет пользователя, привязанного к аккаунту VK (для входа через VK ID)
+func (s *UserStore) FindByVKID(vkID int) (*User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if u.VKID != 0 && u.VKID == vkID {
+			return &u, true
+		}
+	}
+	return nil, false
+}
+
+func (s *UserStore) Get(username string) (*User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, found := s.users[username]
+	if !found {
+		return nil, false
+	}
+	return &u, true
+}
+
+// Save добавляет или обновляет пользователя. Пустой password оставляет прежний пароль
+func (s *UserStore) Save(u User, password string) error {
+	if u.Username == "" {
+		return fmt.Errorf("логин не может быть пустым")
+	}
+	if !ValidRole(u.Role) {
+		return fmt.Errorf("неизвестная роль: %s", u.Role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		u.PasswordHash = string(hash)
+	} else if old, found := s.users[u.Username]; found {
+		u.PasswordHash = old.PasswordHash
+	}
+
+	s.users[u.Username] = u
+	return s.save()
+}
+
+func (s *UserStore) Delete(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, username)
+	return s.save()
+}
+
+func (s *UserStore) List() []User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sorted()
+}
+
+func (s *UserStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users)
+}
+
+// sorted и save вызываются под блокировкой
+func (s *UserStore) sorted() []User {
+	users := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+func (s *UserStore) save() error {
+	return storage.WriteJSON(s.path, s.sorted())
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestUsers(t *testing.T) (*UserStore, string) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	users, err := NewUserStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Save(User{Username: "anna", Name: "Анна", Role: RoleManager, VKID: 42}, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	return users, path
+}
+
+func TestAuthenticate(t *testing.T) {
+	users, _ := newTestUsers(t)
+	if err := users.Save(User{Username: "vk-only", Role: RoleEmployee, VKID: 7}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		ok       bool
+	}{
+		{"верный пароль", "anna", "secret", true},
+		{"неверный пароль", "anna", "Secret", false},
+		{"пустой пароль", "anna", "", false},
+		{"нет пользователя", "boris", "secret", false},
+		{"пользователь без пароля", "vk-only", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := users.Authenticate(tt.username, tt.password)
+			if tt.ok {
+				if err != nil || u.Username != tt.username || u.Role != RoleManager {
+					t.Errorf("Authenticate() = %+v, %v", u, err)
+				}
+				return
+			}
+			if err != ErrInvalidCredentials {
+				t.Errorf("ошибка %v, ожидалась ErrInvalidCredentials", err)
+			}
+		})
+	}
+}
+
+func TestUserStoreSave(t *testing.T) {
+	users, path := newTestUsers(t)
+
+	u, _ := users.Get("anna")
+	if u.PasswordHash == "" || u.PasswordHash == "secret" {
+		t.Fatalf("пароль хранится как %q, ожидался хэш bcrypt", u.PasswordHash)
+	}
+
+	// Пустой пароль при сохранении оставляет прежний
+	if err := users.Save(User{Username: "anna", Name: "Анна П.", Role: RoleAdmin}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Authenticate("anna", "secret"); err != nil {
+		t.Errorf("после правки без пароля вход не работает: %v", err)
+	}
+
+	// Пользователи переживают перезапуск
+	reloaded, err := NewUserStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, found := reloaded.Get("anna"); !found || u.Role != RoleAdmin || u.Name != "Анна П." {
+		t.Errorf("после перезагрузки %+v, %v", u, found)
+	}
+
+	for _, bad := range []User{{Username: "", Role: RoleAdmin}, {Username: "boris", Role: "owner"}} {
+		if err := users.Save(bad, "x"); err == nil {
+			t.Errorf("Save(%+v) без ошибки", bad)
+		}
+	}
+}
+
+func TestFindByVKID(t *testing.T) {
+	users, _ := newTestUsers(t)
+	if u, found := users.FindByVKID(42); !found || u.Username != "anna" {
+		t.Errorf("FindByVKID(42) = %+v, %v", u, found)
+	}
+	// 0 — аккаунт VK не привязан, по нему никого не находим
+	if err := users.Save(User{Username: "boris", Role: RoleEmployee}, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if u, found := users.FindByVKID(0); found {
+		t.Errorf("FindByVKID(0) = %+v", u)
+	}
+}
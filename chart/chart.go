@@ -0,0 +1,277 @@
+package chart
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Размеры холста. SVG масштабируется по ширине контейнера через viewBox
+const (
+	width        = 1000
+	height       = 280
+	paddingLeft  = 60
+	paddingRight = 20
+	paddingTop   = 20
+	paddingBot   = 50
+	gridLines    = 4
+)
+
+// Цвета в тон тёмной теме шаблонов
+const (
+	ColorBlue   = "#1d9bf0"
+	ColorPink   = "#f91880"
+	ColorGreen  = "#00ba7c"
+	ColorGray   = "#8b98a5"
+	ColorYellow = "#ffd400"
+	ColorPurple = "#7856ff"
+	ColorOrange = "#ff7a00"
+	colorGrid   = "#2f3b47"
+	colorText   = "#8b98a5"
+)
+
+// Palette — цвета по порядку для графиков с несколькими сериями
+var Palette = []string{ColorBlue, ColorPink, ColorGreen, ColorYellow, ColorPurple, ColorOrange, ColorGray}
+
+type Bar struct {
+	Label string
+	Value int
+	Link  string
+}
+
+type Point struct {
+	Label string
+	Value int
+}
+
+type Series struct {
+	Name   string
+	Color  string
+	Values []int
+}
+
+// BarChart рисует столбчатую диаграмму. Если у столбца есть Link, он кликабельный
+func BarChart(bars []Bar, color string) template.HTML {
+	if len(bars) == 0 {
+		return ""
+	}
+
+	maxValue := 0
+	for _, b := range bars {
+		maxValue = max(maxValue, b.Value)
+	}
+
+	var sb strings.Builder
+	openSVG(&sb)
+	writeGrid(&sb, maxValue)
+
+	step := plotWidth() / float64(len(bars))
+	barWidth := step * 0.7
+	for i, b := range bars {
+		x := paddingLeft + step*float64(i) + (step-barWidth)/2
+		h := scale(b.Value, maxValue)
+		y := float64(height-paddingBot) - h
+
+		if b.Link != "" {
+			fmt.Fprintf(&sb, `<a href="%s" target="_blank">`, escape(b.Link))
+		}
+		fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="2" fill="%s"><title>%s: %d</title></rect>`,
+			x, y, barWidth, h, color, escape(b.Label), b.Value)
+		if b.Link != "" {
+			sb.WriteString(`</a>`)
+		}
+		writeLabel(&sb, x+barWidth/2, b.Label, len(bars))
+	}
+
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// LineChart рисует линию по точкам с маркерами в каждой точке
+func LineChart(points []Point, color string) template.HTML {
+	if len(points) == 0 {
+		return ""
+	}
+
+	maxValue := 0
+	for _, p := range points {
+		maxValue = max(maxValue, p.Value)
+	}
+
+	var sb strings.Builder
+	openSVG(&sb)
+	writeGrid(&sb, maxValue)
+
+	step := plotWidth()
+	if len(points) > 1 {
+		step = plotWidth() / float64(len(points)-1)
+	}
+
+	coords := make([]string, len(points))
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		xs[i] = paddingLeft + step*float64(i)
+		if len(points) == 1 {
+			xs[i] = paddingLeft + plotWidth()/2
+		}
+		ys[i] = float64(height-paddingBot) - scale(p.Value, maxValue)
+		coords[i] = fmt.Sprintf("%.1f,%.1f", xs[i], ys[i])
+		writeLabel(&sb, xs[i], p.Label, len(points))
+	}
+
+	fmt.Fprintf(&sb, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(coords, " "), color)
+	for i, p := range points {
+		fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="4" fill="%s"><title>%s: %d</title></circle>`,
+			xs[i], ys[i], color, escape(p.Label), p.Value)
+	}
+
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// StackedBarChart рисует столбцы, сложенные из нескольких серий, с легендой
+func StackedBarChart(labels []string, series []Series) template.HTML {
+	if len(labels) == 0 || len(series) == 0 {
+		return ""
+	}
+
+	maxValue := 0
+	for i := range labels {
+		sum := 0
+		for _, s := range series {
+			if i < len(s.Values) {
+				sum += s.Values[i]
+			}
+		}
+		maxValue = max(maxValue, sum)
+	}
+
+	var sb strings.Builder
+	openSVG(&sb)
+	writeGrid(&sb, maxValue)
+
+	step := plotWidth() / float64(len(labels))
+	barWidth := step * 0.6
+	for i, label := range labels {
+		x := paddingLeft + step*float64(i) + (step-barWidth)/2
+		y := float64(height - paddingBot)
+		for _, s := range series {
+			if i >= len(s.Values) || s.Values[i] == 0 {
+				continue
+			}
+			h := scale(s.Values[i], maxValue)
+			y -= h
+			fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s — %s: %d</title></rect>`,
+				x, y, barWidth, h, s.Color, escape(label), escape(s.Name), s.Values[i])
+		}
+		writeLabel(&sb, x+barWidth/2, label, len(labels))
+	}
+
+	writeLegend(&sb, series)
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// GroupedBarChart рисует столбцы серий рядом друг с другом, чтобы их можно было сравнить
+func GroupedBarChart(labels []string, series []Series) template.HTML {
+	if len(labels) == 0 || len(series) == 0 {
+		return ""
+	}
+
+	maxValue := 0
+	for _, s := range series {
+		for _, v := range s.Values {
+			maxValue = max(maxValue, v)
+		}
+	}
+
+	var sb strings.Builder
+	openSVG(&sb)
+	writeGrid(&sb, maxValue)
+
+	step := plotWidth() / float64(len(labels))
+	groupWidth := step * 0.7
+	barWidth := groupWidth / float64(len(series))
+	for i, label := range labels {
+		x := paddingLeft + step*float64(i) + (step-groupWidth)/2
+		for j, s := range series {
+			if i >= len(s.Values) {
+				continue
+			}
+			h := scale(s.Values[i], maxValue)
+			fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="2" fill="%s"><title>%s — %s: %d</title></rect>`,
+				x+barWidth*float64(j), float64(height-paddingBot)-h, barWidth, h, s.Color, escape(label), escape(s.Name), s.Values[i])
+		}
+		writeLabel(&sb, x+groupWidth/2, label, len(labels))
+	}
+
+	writeLegend(&sb, series)
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// writeLegend рисует легенду серий в правом верхнем углу
+func writeLegend(sb *strings.Builder, series []Series) {
+	for i, s := range series {
+		x := width - paddingRight - 120*(len(series)-i)
+		fmt.Fprintf(sb, `<rect x="%d" y="4" width="10" height="10" fill="%s"/>`, x, s.Color)
+		fmt.Fprintf(sb, `<text x="%d" y="13" fill="%s" font-size="12">%s</text>`, x+16, colorText, escape(s.Name))
+	}
+}
+
+func openSVG(sb *strings.Builder) {
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" preserveAspectRatio="xMidYMid meet" font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif">`,
+		width, height)
+}
+
+// writeGrid рисует горизонтальные линии сетки с подписями значений
+func writeGrid(sb *strings.Builder, maxValue int) {
+	for i := 0; i <= gridLines; i++ {
+		value := maxValue * i / gridLines
+		y := float64(height-paddingBot) - scale(value, maxValue)
+		fmt.Fprintf(sb, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s" stroke-width="1"/>`,
+			paddingLeft, y, width-paddingRight, y, colorGrid)
+		fmt.Fprintf(sb, `<text x="%d" y="%.1f" fill="%s" font-size="11" text-anchor="end">%s</text>`,
+			paddingLeft-8, y+4, colorText, shortNumber(value))
+	}
+}
+
+// writeLabel подписывает столбец/точку. При большом количестве подписи поворачиваются
+func writeLabel(sb *strings.Builder, x float64, label string, total int) {
+	y := height - paddingBot + 18
+	if total > 15 {
+		fmt.Fprintf(sb, `<text x="%.1f" y="%d" fill="%s" font-size="10" text-anchor="end" transform="rotate(-45 %.1f %d)">%s</text>`,
+			x, y, colorText, x, y, escape(label))
+		return
+	}
+	fmt.Fprintf(sb, `<text x="%.1f" y="%d" fill="%s" font-size="11" text-anchor="middle">%s</text>`,
+		x, y, colorText, escape(label))
+}
+
+func plotWidth() float64 {
+	return float64(width - paddingLeft - paddingRight)
+}
+
+func scale(value, maxValue int) float64 {
+	if maxValue == 0 {
+		return 0
+	}
+	return float64(value) / float64(maxValue) * float64(height-paddingTop-paddingBot)
+}
+
+func shortNumber(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 10000:
+		return fmt.Sprintf("%dK", n/1000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fK", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func escape(s string) string {
+	return template.HTMLEscapeString(s)
+}
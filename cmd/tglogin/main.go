@@ -0,0 +1,78 @@
+// tglogin — вход в Telegram аккаунт для MTProto клиента. Запускается один раз из корня
+// проекта: go run ./cmd/tglogin. Сессия сохраняется в telegram.mtproto.session_file
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"smm-helper/config"
+	"smm-helper/tg/mtproto"
+
+	"github.com/gotd/td/telegram/auth"
+	tdapi "github.com/gotd/td/tg"
+)
+
+// terminalAuth спрашивает телефон, код и пароль 2FA в терминале
+type terminalAuth struct {
+	in *bufio.Reader
+}
+
+func (a terminalAuth) ask(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := a.in.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (a terminalAuth) Phone(ctx context.Context) (string, error) {
+	return a.ask("Телефон (+7...): ")
+}
+
+func (a terminalAuth) Code(ctx context.Context, sentCode *tdapi.AuthSentCode) (string, error) {
+	return a.ask("Код из Telegram: ")
+}
+
+// Password спрашивается, только если у аккаунта включена двухэтапная проверка
+func (a terminalAuth) Password(ctx context.Context) (string, error) {
+	return a.ask("Пароль двухэтапной проверки: ")
+}
+
+func (a terminalAuth) AcceptTermsOfService(ctx context.Context, tos tdapi.HelpTermsOfService) error {
+	return nil
+}
+
+func (a terminalAuth) SignUp(ctx context.Context) (auth.UserInfo, error) {
+	return auth.UserInfo{}, errors.New("аккаунт не зарегистрирован — сначала войдите в официальном приложении Telegram")
+}
+
+func main() {
+	cfg, err := config.Load("config.json")
+	if err != nil {
+		log.Fatal("Ошибка чтения config.json: ", err)
+	}
+
+	mc := cfg.Telegram.MTProto
+	if mc.AppID == 0 || mc.AppHash == "" {
+		log.Fatal("Задайте telegram.mtproto.app_id и app_hash (https://my.telegram.org/apps)")
+	}
+	// В файле сессии — авторизация аккаунта, каталог закрыт для остальных пользователей
+	if err := os.MkdirAll(filepath.Dir(mc.SessionFile), 0700); err != nil {
+		log.Fatal(err)
+	}
+
+	client := mtproto.NewClient(mc.AppID, mc.AppHash, mc.SessionFile, cfg.Telegram.ChannelID)
+	name, err := client.Login(context.Background(), terminalAuth{in: bufio.NewReader(os.Stdin)})
+	if err != nil {
+		log.Fatal("❌ Вход не удался: ", err)
+	}
+	fmt.Printf("✅ Вход выполнен: %s. Сессия сохранена в %s\n", name, mc.SessionFile)
+}
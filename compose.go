@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"smm-helper/report"
+	"smm-helper/vk"
+)
+
+// ========== ПУБЛИКАЦИЯ ПОСТОВ VK ==========
+
+const (
+	// Вложений в посте VK не больше 10
+	maxPostAttachments = 10
+	postponedCount     = 50
+	composeDateLayout  = "2006-01-02T15:04"
+)
+
+type composePage struct {
+	Error     string
+	Message   string
+	GroupName string
+	// Edit — отложенный пост, открытый для правки
+	Edit      *postponedPost
+	Postponed []postponedPost
+	// Значения формы: пост для правки или введённое до ошибки
+	Text        string
+	PublishDate string
+	Signed      bool
+	MinDate     string
+}
+
+type postponedPost struct {
+	report.PostRef
+	Signed      bool
+	Attachments []vk.Attachment
+}
+
+func newPostponedPost(p vk.Post) postponedPost {
+	return postponedPost{
+		PostRef: report.PostRef{
+			ID:   p.ID,
+			Date: time.Unix(int64(p.Date), 0),
+			Link: report.PostLink(groupID, p.ID),
+			Text: p.Text,
+		},
+		Signed:      p.SignerID != 0,
+		Attachments: p.Attachments,
+	}
+}
+
+func (p postponedPost) DateValue() string {
+	return p.Date.Format(composeDateLayout)
+}
+
+// Photos — вложения-фото с превью: при правке их можно оставить или убрать
+func (p postponedPost) Photos() []composePhoto {
+	photos := []composePhoto{}
+	for _, a := range p.Attachments {
+		if a.Photo != nil {
+			photos = append(photos, composePhoto{ID: a.ID(), URL: a.Photo.URL()})
+		}
+	}
+	return photos
+}
+
+// Other — остальные вложения (видео, ссылки): при правке они сохраняются как есть
+func (p postponedPost) Other() []string {
+	ids := []string{}
+	for _, a := range p.Attachments {
+		if id := a.ID(); a.Photo == nil && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+type composePhoto struct {
+	ID  string
+	URL string
+}
+
+func composeHandler(w http.ResponseWriter, r *http.Request) {
+	page := composePage{Message: r.URL.Query().Get("msg")}
+	editID, _ := strconv.Atoi(r.URL.Query().Get("edit"))
+
+	if r.Method == "POST" {
+		message, errText := publishFromForm(r)
+		if errText == "" {
+			// Перенаправление, чтобы обновление страницы не отправило пост повторно
+			http.Redirect(w, r, "/compose?msg="+url.QueryEscape(message), http.StatusSeeOther)
+			return
+		}
+		// Форма остаётся открытой с введёнными значениями
+		page.Error = errText
+		editID, _ = strconv.Atoi(r.FormValue("post_id"))
+		page.Text = r.FormValue("text")
+		page.PublishDate = r.FormValue("publish_date")
+		page.Signed = r.FormValue("signed") == "1"
+	}
+
+	renderCompose(w, page, editID)
+}
+
+// publishFromForm публикует, планирует или правит пост. Возвращает сообщение об успехе или ошибку
+func publishFromForm(r *http.Request) (string, string) {
+	if err := r.ParseMultipartForm(64 << 20); err != nil && err != http.ErrNotMultipart {
+		return "", "Не удалось прочитать форму: " + err.Error()
+	}
+
+	postID, _ := strconv.Atoi(r.FormValue("post_id"))
+	draft := vk.Draft{
+		Message:     strings.TrimSpace(r.FormValue("text")),
+		Attachments: r.Form["keep"],
+		Signed:      r.FormValue("signed") == "1",
+	}
+
+	if value := r.FormValue("publish_date"); value != "" {
+		date, err := time.ParseInLocation(composeDateLayout, value, time.Local)
+		if err != nil {
+			return "", "Неверное время публикации"
+		}
+		if !date.After(time.Now()) {
+			return "", "Время отложенной публикации должно быть в будущем"
+		}
+		draft.PublishDate = date
+	} else if postID != 0 {
+		return "", "У отложенного поста должно быть время публикации"
+	}
+
+	files := []*multipart.FileHeader{}
+	if r.MultipartForm != nil {
+		files = r.MultipartForm.File["photos"]
+	}
+	if len(draft.Attachments)+len(files) > maxPostAttachments {
+		return "", fmt.Sprintf("В посте VK не больше %d вложений", maxPostAttachments)
+	}
+	if draft.Message == "" && len(draft.Attachments)+len(files) == 0 {
+		return "", "Пост пустой: добавьте текст или фото"
+	}
+
+	for _, fh := range files {
+		data, err := readUpload(fh)
+		if err != nil {
+			return "", "Не удалось прочитать " + fh.Filename + ": " + err.Error()
+		}
+		id, err := vkClient.UploadWallPhoto(-groupID, fh.Filename, data)
+		if err != nil {
+			return "", err.Error()
+		}
+		draft.Attachments = append(draft.Attachments, id)
+	}
+
+	if postID != 0 {
+		if err := vkClient.EditPost(groupID, postID, draft); err != nil {
+			return "", err.Error()
+		}
+		fmt.Printf("✍️ Отложенный пост %d изменён\n", postID)
+		return "Отложенный пост сохранён, выйдет " + draft.PublishDate.Format("02.01.2006 в 15:04"), ""
+	}
+
+	id, err := vkClient.Publish(groupID, draft)
+	if err != nil {
+		return "", err.Error()
+	}
+	if draft.PublishDate.IsZero() {
+		fmt.Printf("✍️ Опубликован пост %d\n", id)
+		invalidateVKCaches()
+		vkWall.Reset()
+		return "Пост опубликован: " + report.PostLink(groupID, id), ""
+	}
+	fmt.Printf("✍️ Запланирован пост %d на %s\n", id, draft.PublishDate.Format("02.01.2006 15:04"))
+	return "Пост запланирован на " + draft.PublishDate.Format("02.01.2006 в 15:04"), ""
+}
+
+func readUpload(fh *multipart.FileHeader) ([]byte, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func deletePostponedHandler(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(r.FormValue("post_id"))
+	if postID == 0 {
+		http.Error(w, "Не указан пост", http.StatusBadRequest)
+		return
+	}
+	if err := vkClient.DeletePost(groupID, postID); err != nil {
+		renderCompose(w, composePage{Error: err.Error()}, 0)
+		return
+	}
+	fmt.Printf("✍️ Отложенный пост %d удалён\n", postID)
+	http.Redirect(w, r, "/compose", http.StatusSeeOther)
+}
+
+// renderCompose дополняет страницу списком отложенных постов. editID — пост, открытый для правки
+func renderCompose(w http.ResponseWriter, page composePage, editID int) {
+	postponed, err := vkClient.GetPostponed(groupID, postponedCount)
+	if err != nil && page.Error == "" {
+		page.Error = "Не удалось загрузить отложенные посты: " + err.Error()
+	}
+	for _, p := range postponed {
+		item := newPostponedPost(p)
+		page.Postponed = append(page.Postponed, item)
+		if p.ID != editID {
+			continue
+		}
+		page.Edit = &item
+		if page.Error == "" {
+			page.Text = item.Text
+			page.PublishDate = item.DateValue()
+			page.Signed = item.Signed
+		}
+	}
+
+	page.GroupName = groupName
+	page.MinDate = time.Now().Add(time.Minute).Format(composeDateLayout)
+	tmpl := template.Must(template.ParseFiles("templates/compose.html"))
+	tmpl.Execute(w, page)
+}
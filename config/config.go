@@ -0,0 +1,277 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+type Config struct {
+	Auth      AuthConfig      `json:"auth"`
+	KPI       KPIConfig       `json:"kpi"`
+	Reminders RemindersConfig `json:"reminders"`
+	Digests   DigestsConfig   `json:"digests"`
+	Alerts    AlertsConfig    `json:"alerts"`
+	Telegram  TelegramConfig  `json:"telegram"`
+	// VKCallback — приём событий сообщества в реальном времени на /vk/callback
+	VKCallback VKCallbackConfig `json:"vk_callback"`
+	// VKLongPoll — те же события без публичного адреса, через Bots Long Poll
+	VKLongPoll VKLongPollConfig `json:"vk_longpoll"`
+	AI         AIConfig         `json:"ai"`
+}
+
+// AIConfig — контент-план через GigaChat. Без ключа новые планы не генерируются,
+// но сохранённые можно править. Theme — тематика группы по умолчанию
+type AIConfig struct {
+	GigaChatKey string `json:"gigachat_key"`
+	Theme       string `json:"theme"`
+	// PlansFile — сохранённые планы с версиями пунктов
+	PlansFile string `json:"plans_file"`
+}
+
+// VKCallbackConfig — сервер Callback API в настройках сообщества (Управление → Работа с API).
+// Confirmation — строка, которую должен вернуть сервер, Secret — секретный ключ.
+// Нужные типы событий: wall_post_new, wall_repost, wall_reply_new, like_add, like_remove
+type VKCallbackConfig struct {
+	Enabled      bool   `json:"enabled"`
+	Confirmation string `json:"confirmation"`
+	Secret       string `json:"secret"`
+}
+
+// VKLongPollConfig — Bots Long Poll API: в настройках сообщества включите Long Poll API
+// и те же типы событий, что для Callback API. Token — ключ сообщества; если пустой,
+// используется токен приложения. APIURL можно подменить тестовым сервером
+type VKLongPollConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Token       string `json:"token"`
+	APIURL      string `json:"api_url"`
+	WaitSeconds int    `json:"wait_seconds"`
+}
+
+type AuthConfig struct {
+	UsersFile       string   `json:"users_file"`
+	SessionTTLHours int      `json:"session_ttl_hours"`
+	VKID            VKIDAuth `json:"vk_id"`
+	// LinkSecret подписывает персональные ссылки сотрудников. Смена секрета отзывает все ссылки
+	LinkSecret string `json:"link_secret"`
+}
+
+// KPIConfig — где хранятся цели по активности сотрудников (редактируются на /kpi)
+type KPIConfig struct {
+	RulesFile string `json:"rules_file"`
+}
+
+// RemindersConfig — бот, который напоминает сотрудникам о неотработанных постах
+type RemindersConfig struct {
+	Enabled bool   `json:"enabled"`
+	Channel string `json:"channel"` // vk или telegram
+	// DryRun печатает сообщения в лог вместо отправки
+	DryRun       bool `json:"dry_run"`
+	AfterHours   int  `json:"after_hours"`
+	MaxAgeHours  int  `json:"max_age_hours"`
+	CheckMinutes int  `json:"check_minutes"`
+	// Тихие часы по местному времени, например с 22 до 9
+	QuietFrom int    `json:"quiet_from"`
+	QuietTo   int    `json:"quiet_to"`
+	StateFile string `json:"state_file"`
+	// PublicURL — адрес дашборда для ссылок в сообщениях, например https://smm.example.com
+	PublicURL string            `json:"public_url"`
+	VK        VKMessagesConfig  `json:"vk"`
+	Telegram  TelegramBotConfig `json:"telegram"`
+}
+
+// VKMessagesConfig — токен сообщества с доступом к сообщениям. APIURL можно подменить тестовым сервером
+type VKMessagesConfig struct {
+	Token  string `json:"token"`
+	APIURL string `json:"api_url"`
+}
+
+// TelegramBot — токен бота. APIURL можно подменить тестовым сервером
+type TelegramBot struct {
+	BotToken string `json:"bot_token"`
+	APIURL   string `json:"api_url"`
+}
+
+// TelegramBotConfig — бот и chat_id сотрудников по их VK ID
+type TelegramBotConfig struct {
+	TelegramBot
+	Chats map[int]int64 `json:"chats"`
+}
+
+// DigestsConfig — рассылка недельных и месячных дайджестов по расписанию
+type DigestsConfig struct {
+	Enabled   bool             `json:"enabled"`
+	SMTP      SMTPConfig       `json:"smtp"`
+	Telegram  TelegramBot      `json:"telegram"`
+	Schedules []DigestSchedule `json:"schedules"`
+}
+
+// SMTPConfig — почтовый сервер. Без Username письма уходят без авторизации
+type SMTPConfig struct {
+	Addr     string `json:"addr"`
+	From     string `json:"from"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// DigestSchedule — одна рассылка. Cron — пять полей, например "0 9 * * 1"
+type DigestSchedule struct {
+	Name       string            `json:"name"`
+	Period     string            `json:"period"` // week или month
+	Cron       string            `json:"cron"`
+	Recipients []DigestRecipient `json:"recipients"`
+}
+
+// DigestRecipient — почта и/или чат Telegram. Attach: csv или пусто (PDF не поддерживается)
+type DigestRecipient struct {
+	Email          string `json:"email"`
+	TelegramChatID int64  `json:"telegram_chat_id"`
+	Attach         string `json:"attach"`
+}
+
+// AlertsConfig — история метрик постов и оповещения об аномалиях
+type AlertsConfig struct {
+	Enabled         bool   `json:"enabled"`
+	HistoryFile     string `json:"history_file"`
+	LogFile         string `json:"log_file"`
+	SnapshotMinutes int    `json:"snapshot_minutes"`
+	AfterHours      int    `json:"after_hours"`
+	// Method — zscore или iqr. Threshold — |z| или множитель межквартильного размаха
+	Method           string  `json:"method"`
+	Threshold        float64 `json:"threshold"`
+	MinSamples       int     `json:"min_samples"`
+	ReachDropPercent float64 `json:"reach_drop_percent"`
+	// Каналы: чаты Telegram и личные сообщения VK от имени сообщества
+	Telegram      TelegramBot      `json:"telegram"`
+	TelegramChats []int64          `json:"telegram_chats"`
+	VK            VKMessagesConfig `json:"vk"`
+	VKUserIDs     []int64          `json:"vk_user_ids"`
+}
+
+// TelegramConfig — канал и сбор его постов ботом. Бот должен быть администратором канала.
+// Mode: polling (getUpdates), webhook или пусто — посты не собираются
+type TelegramConfig struct {
+	TelegramBot
+	ChannelID     string `json:"channel_id"` // @username или числовой ID
+	PostsFile     string `json:"posts_file"`
+	Mode          string `json:"mode"`
+	WebhookURL    string `json:"webhook_url"` // публичный адрес /tg/webhook
+	WebhookSecret string `json:"webhook_secret"`
+	// DiscussionID — группа обсуждения канала (@username или ID). Бот должен в ней состоять,
+	// чтобы видеть комментарии сотрудников
+	DiscussionID string `json:"discussion_id"`
+	// Employees — сотрудники в Telegram: @username или числовой ID
+	Employees []string `json:"employees"`
+	// Scrape — листание публичной страницы t.me/s для отчётов за период
+	Scrape ScrapeConfig `json:"scrape"`
+	// MTProto — чтение канала от имени пользователя: вся история и реакции по эмодзи
+	MTProto MTProtoConfig `json:"mtproto"`
+	// Crosspost — перенос новых постов VK в канал ботом канала
+	Crosspost CrosspostConfig `json:"crosspost"`
+}
+
+// CrosspostConfig — кросспостинг VK → Telegram. Переносятся только посты, вышедшие после
+// первого запуска; журнал в LedgerFile не даёт опубликовать пост дважды.
+// Посты с хештегами из ExcludeTags (например #тольковк) остаются только в VK
+type CrosspostConfig struct {
+	Enabled bool `json:"enabled"`
+	// DryRun печатает сообщения в лог вместо отправки
+	DryRun       bool     `json:"dry_run"`
+	CheckMinutes int      `json:"check_minutes"`
+	LedgerFile   string   `json:"ledger_file"`
+	ExcludeTags  []string `json:"exclude_tags"`
+}
+
+// MTProtoConfig — приложение с my.telegram.org и файл сессии. Если AppID = 0, MTProto не используется.
+// Войти в аккаунт нужно один раз: go run ./cmd/tglogin
+type MTProtoConfig struct {
+	AppID       int    `json:"app_id"`
+	AppHash     string `json:"app_hash"`
+	SessionFile string `json:"session_file"`
+}
+
+// ScrapeConfig — вежливое чтение t.me/s: пауза между страницами, глубина и кэш
+type ScrapeConfig struct {
+	DelayMS      int `json:"delay_ms"`
+	MaxPages     int `json:"max_pages"`
+	CacheMinutes int `json:"cache_minutes"`
+}
+
+// VKIDAuth — настройки входа через VK ID. Если ClientID пустой, кнопка входа через VK скрыта
+type VKIDAuth struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RedirectURL  string `json:"redirect_url"`
+}
+
+func Default() *Config {
+	return &Config{
+		Auth: AuthConfig{
+			UsersFile:       "data/users.json",
+			SessionTTLHours: 24 * 7,
+		},
+		KPI: KPIConfig{
+			RulesFile: "data/kpi.json",
+		},
+		Telegram: TelegramConfig{
+			ChannelID: "@kait_20_official",
+			PostsFile: "data/tg_posts.json",
+			Scrape: ScrapeConfig{
+				DelayMS:      1000,
+				MaxPages:     50,
+				CacheMinutes: 10,
+			},
+			MTProto: MTProtoConfig{
+				SessionFile: "data/tg_session.json",
+			},
+			Crosspost: CrosspostConfig{
+				CheckMinutes: 5,
+				LedgerFile:   "data/crosspost.json",
+			},
+		},
+		AI: AIConfig{
+			PlansFile: "data/content_plans.json",
+		},
+		VKLongPoll: VKLongPollConfig{
+			WaitSeconds: 25,
+		},
+		Alerts: AlertsConfig{
+			HistoryFile:      "data/history.json",
+			LogFile:          "data/alerts.json",
+			SnapshotMinutes:  30,
+			AfterHours:       6,
+			Method:           "zscore",
+			Threshold:        2.5,
+			MinSamples:       10,
+			ReachDropPercent: 50,
+		},
+		Reminders: RemindersConfig{
+			Channel:      "vk",
+			AfterHours:   6,
+			MaxAgeHours:  72,
+			CheckMinutes: 30,
+			QuietFrom:    22,
+			QuietTo:      9,
+			StateFile:    "data/reminders.json",
+		},
+	}
+}
+
+// Load читает конфиг из JSON файла поверх значений по умолчанию.
+// Если файла нет, возвращаются значения по умолчанию
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
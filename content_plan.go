@@ -0,0 +1,408 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"smm-helper/ai"
+	"smm-helper/auth"
+	"smm-helper/plan"
+	"smm-helper/report"
+
+	"github.com/gorilla/mux"
+)
+
+// ========== КОНТЕНТ-ПЛАН ==========
+
+const (
+	// Планировщик называет дни недели, поэтому план — не больше чем на неделю
+	maxPlanDays = 7
+	// Сколько последних постов VK и сколько символов каждого передаём в запрос
+	planRecentPosts  = 10
+	planRecentLength = 300
+	planWeekLayout   = "2006-01-02"
+)
+
+var (
+	contentPlanner *ai.ContentPlanner
+	planStore      *plan.Store
+	planPublisher  *plan.Publisher
+
+	// Итог последней отправки плана — показывается один раз после перенаправления
+	planPublishMu      sync.Mutex
+	planPublishResults = map[int][]planPublishResult{}
+)
+
+func initContentPlanner() {
+	if cfg.AI.GigaChatKey != "" {
+		contentPlanner = ai.NewContentPlanner(cfg.AI.GigaChatKey)
+	}
+
+	var err error
+	if planStore, err = plan.NewStore(cfg.AI.PlansFile); err != nil {
+		log.Fatal("Ошибка загрузки контент-планов: ", err)
+	}
+}
+
+type contentPlanPage struct {
+	Enabled bool
+	Days    int
+	MaxDays int
+	Theme   string
+	Notes   string
+	Error   string
+	Plan    []ai.DayPlan
+	// RecentPosts — сколько постов VK ушло в запрос как пример
+	RecentPosts int
+	// Сохранение: название и понедельник недели по умолчанию — следующая неделя
+	Name  string
+	Week  string
+	Plans []plan.Plan
+}
+
+func contentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	next := plan.WeekStart(time.Now()).AddDate(0, 0, 7)
+	page := contentPlanPage{
+		Enabled: contentPlanner != nil,
+		Days:    maxPlanDays,
+		MaxDays: maxPlanDays,
+		Theme:   cfg.AI.Theme,
+		Name:    "План на неделю " + next.Format("02.01"),
+		Week:    next.Format(planWeekLayout),
+	}
+
+	if r.Method == "POST" && page.Enabled {
+		page.Theme = strings.TrimSpace(r.FormValue("theme"))
+		page.Notes = strings.TrimSpace(r.FormValue("notes"))
+		if d, _ := strconv.Atoi(r.FormValue("days")); d > 0 && d <= maxPlanDays {
+			page.Days = d
+		}
+
+		plan, recent, err := generatePlan(page.Theme, page.Notes, page.Days)
+		page.RecentPosts = recent
+		if err != nil {
+			page.Error = err.Error()
+		}
+		page.Plan = plan
+	}
+
+	renderContentPlan(w, page)
+}
+
+// generatePlan запрашивает план у GigaChat, добавляя к запросу последние посты группы.
+// Возвращает план и сколько постов ушло в запрос
+func generatePlan(theme, notes string, days int) ([]ai.DayPlan, int, error) {
+	recent, err := recentPostTexts()
+	if err != nil {
+		return nil, 0, fmt.Errorf("Не удалось загрузить посты VK: %v", err)
+	}
+
+	fmt.Printf("🤖 Контент-план на %d дн. (тема: %s)\n", days, theme)
+	dayPlans, err := contentPlanner.GenerateContentPlan(ai.ContentPlanRequest{
+		GroupName:      groupName,
+		GroupTheme:     theme,
+		RecentPosts:    recent,
+		AdditionalInfo: notes,
+		DaysCount:      days,
+	})
+	if err != nil {
+		return nil, len(recent), err
+	}
+	if len(dayPlans) == 0 {
+		return nil, len(recent), fmt.Errorf("GigaChat ответил, но план не удалось разобрать — попробуйте ещё раз")
+	}
+	return dayPlans, len(recent), nil
+}
+
+// recentPostTexts — тексты последних постов группы, чтобы план был в её стиле
+func recentPostTexts() ([]string, error) {
+	posts, err := vkClient.GetWallPosts(groupID, planRecentPosts)
+	if err != nil {
+		return nil, err
+	}
+	texts := []string{}
+	for _, p := range posts {
+		text := strings.TrimSpace(p.Text)
+		if text == "" {
+			continue
+		}
+		if runes := []rune(text); len(runes) > planRecentLength {
+			text = string(runes[:planRecentLength]) + "..."
+		}
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
+
+func renderContentPlan(w http.ResponseWriter, page contentPlanPage) {
+	page.Plans = planStore.List()
+	tmpl := template.Must(template.ParseFiles("templates/content_plan.html"))
+	tmpl.Execute(w, page)
+}
+
+// savePlanHandler сохраняет сгенерированный план вместе с правками, сделанными на странице.
+// Исходный ответ ИИ приходит в скрытых полях и становится первой версией каждого пункта
+func savePlanHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	week, err := time.ParseInLocation(planWeekLayout, r.FormValue("week"), time.Local)
+	if err != nil {
+		http.Error(w, "Неверная неделя", http.StatusBadRequest)
+		return
+	}
+	week = plan.WeekStart(week)
+
+	session, _ := auth.FromContext(r.Context())
+	now := time.Now()
+	p := plan.Plan{
+		Name:    strings.TrimSpace(r.FormValue("name")),
+		Week:    week,
+		Theme:   r.FormValue("theme"),
+		Notes:   r.FormValue("notes"),
+		Created: now,
+	}
+
+	days := r.Form["item_day"]
+	for i, day := range days {
+		item := plan.NewItem(plan.DayDate(week, day, i), planContent(r, "ai_", i), now)
+		if edited := planContent(r, "item_", i); edited != item.Content {
+			item.Content = edited
+			item.Versions = append(item.Versions, plan.Version{Content: edited, At: now, Source: plan.SourceEdit, Author: session.Username})
+		}
+		p.Items = append(p.Items, item)
+	}
+
+	id, err := planStore.Create(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Printf("🤖 Сохранён контент-план %d «%s»\n", id, p.Name)
+	http.Redirect(w, r, fmt.Sprintf("/content_plan/%d", id), http.StatusSeeOther)
+}
+
+// planContent — i-й пункт формы. prefix: item_ — с правками, ai_ — как ответил ИИ
+func planContent(r *http.Request, prefix string, i int) plan.Content {
+	value := func(field string) string {
+		values := r.Form[prefix+field]
+		if i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+	return plan.Content{
+		Time:     value("time"),
+		Theme:    value("theme"),
+		Text:     value("text"),
+		Hashtags: value("hashtags"),
+		MediaTip: value("media"),
+	}
+}
+
+type planPage struct {
+	Plan     plan.Plan
+	Items    []planItem
+	Statuses []plan.Status
+	Enabled  bool
+	Error    string
+	// Results — итог отправки утверждённых пунктов в VK
+	Results []planPublishResult
+}
+
+// planItem — пункт с номером для адресов /content_plan/{id}/items/{n}
+type planItem struct {
+	plan.Item
+	N    int
+	Link string // пост VK, если пункт уже отправлен
+}
+
+type planPublishResult struct {
+	plan.Result
+	Link string
+}
+
+// Approved — сколько пунктов уйдёт в VK по кнопке
+func (p planPage) Approved() int {
+	return p.Plan.Count(plan.Approved)
+}
+
+func planViewHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	p, found := planStore.Get(id)
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	renderPlan(w, planPage{Plan: p, Error: r.URL.Query().Get("error"), Results: takePublishResults(id)})
+}
+
+func renderPlan(w http.ResponseWriter, page planPage) {
+	page.Statuses = plan.Statuses
+	page.Enabled = contentPlanner != nil
+	for i, item := range page.Plan.Items {
+		view := planItem{Item: item, N: i + 1}
+		if item.PostID != 0 {
+			view.Link = report.PostLink(groupID, item.PostID)
+		}
+		page.Items = append(page.Items, view)
+	}
+
+	tmpl := template.Must(template.ParseFiles("templates/content_plan_view.html"))
+	tmpl.Execute(w, page)
+}
+
+// planPublishHandler создаёт отложенные посты VK из утверждённых пунктов плана.
+// Пункт, у которого пост уже есть, не дублируется: у поста меняются текст и время
+func planPublishHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	published, found := planPublisher.Publish(id, time.Now())
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	results := []planPublishResult{}
+	for _, result := range published {
+		view := planPublishResult{Result: result}
+		if result.PostID != 0 {
+			view.Link = report.PostLink(groupID, result.PostID)
+		}
+		results = append(results, view)
+	}
+
+	target := fmt.Sprintf("/content_plan/%d", id)
+	if len(results) == 0 {
+		target += "?error=" + url.QueryEscape("В плане нет утверждённых пунктов — утвердите посты, которые нужно отправить в VK")
+	}
+	// Перенаправление, чтобы обновление страницы не отправило план повторно
+	planPublishMu.Lock()
+	planPublishResults[id] = results
+	planPublishMu.Unlock()
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// takePublishResults забирает итог последней отправки плана
+func takePublishResults(id int) []planPublishResult {
+	planPublishMu.Lock()
+	defer planPublishMu.Unlock()
+	results := planPublishResults[id]
+	delete(planPublishResults, id)
+	return results
+}
+
+// planItemHandler сохраняет правку пункта и его статус
+func planItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, index := planItemVars(r)
+	session, _ := auth.FromContext(r.Context())
+	c := plan.Content{
+		Time:     strings.TrimSpace(r.FormValue("time")),
+		Theme:    strings.TrimSpace(r.FormValue("theme")),
+		Text:     strings.TrimSpace(r.FormValue("text")),
+		Hashtags: strings.TrimSpace(r.FormValue("hashtags")),
+		MediaTip: strings.TrimSpace(r.FormValue("media")),
+	}
+
+	err := planStore.UpdateItem(id, index, c, plan.Status(r.FormValue("status")), plan.SourceEdit, session.Username, time.Now())
+	redirectToItem(w, r, id, index, err)
+}
+
+// planRegenerateHandler просит ИИ заново придумать один пост плана
+func planRegenerateHandler(w http.ResponseWriter, r *http.Request) {
+	id, index := planItemVars(r)
+	p, found := planStore.Get(id)
+	if !found || index < 0 || index >= len(p.Items) {
+		http.NotFound(w, r)
+		return
+	}
+	if contentPlanner == nil {
+		redirectToItem(w, r, id, index, fmt.Errorf("генерация выключена: не задан ai.gigachat_key"))
+		return
+	}
+
+	item := p.Items[index]
+	notes := strings.TrimSpace(p.Notes + "\n" + r.FormValue("notes"))
+	notes += fmt.Sprintf("\nНужен один пост на %s. Предложи другой вариант, не повторяй тему «%s».", item.DayTitle(), item.Theme)
+
+	dayPlans, _, err := generatePlan(p.Theme, notes, 1)
+	if err != nil {
+		redirectToItem(w, r, id, index, err)
+		return
+	}
+	c := plan.Content{
+		Time:     dayPlans[0].Time,
+		Theme:    dayPlans[0].Theme,
+		Text:     dayPlans[0].Text,
+		Hashtags: dayPlans[0].Hashtags,
+		MediaTip: dayPlans[0].MediaTip,
+	}
+	if c.Time == "" {
+		c.Time = item.Time
+	}
+
+	// Новый черновик снова требует утверждения
+	err = planStore.UpdateItem(id, index, c, plan.Draft, plan.SourceAI, "", time.Now())
+	redirectToItem(w, r, id, index, err)
+}
+
+type planHistoryPage struct {
+	Plan plan.Plan
+	N    int
+	Item plan.Item
+	// Draft — последний черновик ИИ, с ним сравнивается текущий текст
+	Draft    plan.Version
+	HasDraft bool
+	Versions []plan.Version // новые первыми
+}
+
+func planHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, index := planItemVars(r)
+	p, found := planStore.Get(id)
+	if !found || index < 0 || index >= len(p.Items) {
+		http.NotFound(w, r)
+		return
+	}
+
+	item := p.Items[index]
+	page := planHistoryPage{Plan: p, N: index + 1, Item: item, Versions: []plan.Version{}}
+	page.Draft, page.HasDraft = item.AIDraft()
+	for i := len(item.Versions) - 1; i >= 0; i-- {
+		page.Versions = append(page.Versions, item.Versions[i])
+	}
+
+	tmpl := template.Must(template.ParseFiles("templates/content_plan_history.html"))
+	tmpl.Execute(w, page)
+}
+
+func deletePlanHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if err := planStore.Delete(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	planPublishMu.Lock()
+	delete(planPublishResults, id)
+	planPublishMu.Unlock()
+	http.Redirect(w, r, "/content_plan", http.StatusSeeOther)
+}
+
+func planItemVars(r *http.Request) (int, int) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	n, _ := strconv.Atoi(vars["n"])
+	return id, n - 1
+}
+
+// redirectToItem возвращает к пункту плана, ошибку показывает над планом
+func redirectToItem(w http.ResponseWriter, r *http.Request, id, index int, err error) {
+	target := fmt.Sprintf("/content_plan/%d", id)
+	if err != nil {
+		target += "?error=" + url.QueryEscape(err.Error())
+	}
+	http.Redirect(w, r, fmt.Sprintf("%s#item-%d", target, index+1), http.StatusSeeOther)
+}
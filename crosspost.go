@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"smm-helper/crosspost"
+	"smm-helper/vk"
+)
+
+// ========== КРОССПОСТИНГ VK → TELEGRAM ==========
+
+// Сколько последних постов стены смотрим при каждой проверке
+const crosspostWallCount = 20
+
+func initCrosspost() {
+	cc := cfg.Telegram.Crosspost
+	if !cc.Enabled {
+		return
+	}
+	if cfg.Telegram.BotToken == "" {
+		log.Fatal("Для кросспостинга задайте telegram.bot_token: бот должен быть администратором канала")
+	}
+
+	ledger, err := crosspost.LoadLedger(cc.LedgerFile)
+	if err != nil {
+		log.Fatal("Ошибка загрузки журнала кросспостинга: ", err)
+	}
+
+	crossPoster = crosspost.NewPoster(tgClient, cfg.Telegram.ChannelID, ledger)
+	crossPoster.ExcludeTags = cc.ExcludeTags
+	crossPoster.DryRun = cc.DryRun
+	crossPoster.Wall = func() ([]vk.Post, error) {
+		return vkClient.GetWallPosts(groupID, crosspostWallCount)
+	}
+}
+
+// startCrosspost запускает опрос стены VK в фоне, если кросспостинг включён
+func startCrosspost() {
+	if crossPoster == nil {
+		return
+	}
+	interval := time.Duration(cfg.Telegram.Crosspost.CheckMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	mode := "публикация"
+	if crossPoster.DryRun {
+		mode = "dry-run"
+	}
+	fmt.Printf("🔁 Кросспостинг VK → %s: %s, проверка каждые %v, посты с %s\n",
+		crossPoster.ChatID, mode, interval, crossPoster.Ledger().Since().Format("02.01.2006 15:04"))
+	go crossPoster.Run(context.Background(), interval)
+}
@@ -0,0 +1,108 @@
+package crosspost
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"smm-helper/tg"
+	"smm-helper/vk"
+)
+
+var (
+	// [id1|Имя], [club1|Группа], [https://example.com|текст]
+	vkLinkRe = regexp.MustCompile(`\[([^\[\]|]+)\|([^\[\]]+)\]`)
+	// #новости@kait_20_official — хештег внутри сообщества VK
+	vkHashtagRe  = regexp.MustCompile(`(#[\p{L}\p{N}_]+)@[\w.]+`)
+	hashtagRe    = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+	screenNameRe = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+)
+
+// FormatHTML переводит текст поста VK в HTML для Telegram: экранирует спецсимволы,
+// упоминания и ссылки в разметке VK делает ссылками, у хештегов убирает адрес сообщества
+func FormatHTML(text string) string {
+	text = vkHashtagRe.ReplaceAllString(text, "$1")
+
+	var b strings.Builder
+	last := 0
+	for _, m := range vkLinkRe.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		target, label := text[m[2]:m[3]], text[m[4]:m[5]]
+		if link := vkLinkURL(target); link != "" {
+			fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(link), html.EscapeString(label))
+		} else {
+			b.WriteString(html.EscapeString(text[m[0]:m[1]]))
+		}
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return strings.TrimSpace(b.String())
+}
+
+// vkLinkURL — адрес из левой части разметки [адрес|текст]. Пустая строка — это не ссылка
+func vkLinkURL(target string) string {
+	target = strings.TrimSpace(target)
+	switch {
+	case strings.HasPrefix(target, "https://"), strings.HasPrefix(target, "http://"):
+		return target
+	case strings.HasPrefix(target, "vk.com/"), strings.HasPrefix(target, "m.vk.com/"):
+		return "https://" + target
+	case screenNameRe.MatchString(target):
+		// id1, club1, public1 и короткие имена
+		return "https://vk.com/" + target
+	}
+	return ""
+}
+
+// Message — текст для Telegram: сам пост и ссылки на вложения, которые нельзя загрузить
+// (видео и внешние ссылки, если их ещё нет в тексте)
+func Message(p vk.Post) string {
+	lines := []string{FormatHTML(p.Text)}
+	for _, a := range p.Attachments {
+		switch {
+		case a.Video != nil:
+			lines = append(lines, "🎬 "+html.EscapeString(a.Video.URL()))
+		case a.Link != nil && !strings.Contains(p.Text, a.Link.URL):
+			lines = append(lines, "🔗 "+html.EscapeString(a.Link.URL))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n\n"))
+}
+
+// Photos — ссылки на фото поста в лучшем качестве, не больше альбома Telegram
+func Photos(p vk.Post) []string {
+	urls := []string{}
+	for _, a := range p.Attachments {
+		if a.Photo == nil {
+			continue
+		}
+		if url := a.Photo.URL(); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) > tg.MaxAlbumSize {
+		urls = urls[:tg.MaxAlbumSize]
+	}
+	return urls
+}
+
+// Excluded — первый хештег исключения, найденный в посте, без учёта регистра
+func Excluded(text string, tags []string) string {
+	lower := strings.ToLower(vkHashtagRe.ReplaceAllString(text, "$1"))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if !strings.HasPrefix(tag, "#") {
+			tag = "#" + tag
+		}
+		for _, found := range hashtagRe.FindAllString(lower, -1) {
+			if found == tag {
+				return tag
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package crosspost
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"smm-helper/storage"
+)
+
+// После стольких неудачных попыток пост больше не отправляется
+const maxAttempts = 3
+
+// Entry — что стало с постом VK: опубликован в Telegram, пропущен или не отправился
+type Entry struct {
+	VKPostID     int       `json:"vk_post_id"`
+	TGMessageIDs []int     `json:"tg_message_ids,omitempty"`
+	At           time.Time `json:"at"`
+	Skipped      string    `json:"skipped,omitempty"` // причина пропуска
+	Error        string    `json:"error,omitempty"`   // последняя ошибка отправки
+	Attempts     int       `json:"attempts,omitempty"`
+}
+
+// Ledger — журнал кросспостинга. По нему один пост VK не публикуется в Telegram дважды
+type Ledger struct {
+	path string
+	data ledgerData
+	mu   sync.RWMutex
+}
+
+type ledgerData struct {
+	// Since — время первого запуска: более старые посты не переносятся
+	Since time.Time      `json:"since"`
+	Posts map[int]*Entry `json:"posts"`
+}
+
+func LoadLedger(path string) (*Ledger, error) {
+	l := &Ledger{path: path}
+	if err := storage.ReadJSON(path, &l.data); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	if l.data.Posts == nil {
+		l.data.Posts = map[int]*Entry{}
+	}
+	if l.data.Since.IsZero() {
+		l.data.Since = time.Now()
+		if err := l.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *Ledger) Since() time.Time {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.data.Since
+}
+
+// Done — пост уже опубликован, пропущен или исчерпал попытки
+func (l *Ledger) Done(vkPostID int) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	e, found := l.data.Posts[vkPostID]
+	return found && (len(e.TGMessageIDs) > 0 || e.Skipped != "" || e.Attempts >= maxAttempts)
+}
+
+func (l *Ledger) Posted(vkPostID int, messageIDs []int, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data.Posts[vkPostID] = &Entry{VKPostID: vkPostID, TGMessageIDs: messageIDs, At: at}
+}
+
+func (l *Ledger) Skip(vkPostID int, reason string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data.Posts[vkPostID] = &Entry{VKPostID: vkPostID, Skipped: reason, At: at}
+}
+
+// Failed запоминает ошибку и считает попытку
+func (l *Ledger) Failed(vkPostID int, err error, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, found := l.data.Posts[vkPostID]
+	if !found {
+		e = &Entry{VKPostID: vkPostID}
+		l.data.Posts[vkPostID] = e
+	}
+	e.Attempts++
+	e.Error = err.Error()
+	e.At = at
+}
+
+func (l *Ledger) Save() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return storage.WriteJSON(l.path, l.data)
+}
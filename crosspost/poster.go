@@ -0,0 +1,219 @@
+package crosspost
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"smm-helper/tg"
+	"smm-helper/vk"
+)
+
+// Poster переносит новые посты стены VK в канал Telegram
+type Poster struct {
+	Telegram *tg.Client
+	ChatID   string
+	// Wall — последние посты стены VK
+	Wall func() ([]vk.Post, error)
+	// ExcludeTags — посты с этими хештегами остаются только в VK
+	ExcludeTags []string
+	// DryRun печатает сообщения в лог вместо отправки
+	DryRun bool
+
+	ledger *Ledger
+	// В dry-run отметки только в памяти, как у напоминаний
+	dryRunSent map[int]bool
+	httpClient *http.Client
+	// Один пост может прийти одновременно из опроса стены и из Callback API
+	mu sync.Mutex
+}
+
+func NewPoster(client *tg.Client, chatID string, ledger *Ledger) *Poster {
+	return &Poster{
+		Telegram:   client,
+		ChatID:     chatID,
+		ledger:     ledger,
+		dryRunSent: map[int]bool{},
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *Poster) Ledger() *Ledger {
+	return p.ledger
+}
+
+// Run проверяет стену каждые interval, пока не отменён ctx
+func (p *Poster) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		posted, err := p.Check(ctx)
+		if err != nil {
+			fmt.Println("❌ Кросспостинг:", err)
+		} else if posted > 0 {
+			fmt.Printf("🔁 Кросспостинг: перенесено в Telegram постов %d\n", posted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check переносит новые посты стены от старых к новым, чтобы в канале сохранился порядок
+func (p *Poster) Check(ctx context.Context) (int, error) {
+	posts, err := p.Wall()
+	if err != nil {
+		return 0, fmt.Errorf("стена VK: %v", err)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].Date < posts[j].Date })
+
+	posted := 0
+	for _, post := range posts {
+		ok, err := p.Handle(ctx, post)
+		if err != nil {
+			fmt.Printf("❌ Кросспостинг поста %d: %v\n", post.ID, err)
+			continue
+		}
+		if ok {
+			posted++
+		}
+	}
+	return posted, nil
+}
+
+// Handle публикует пост в Telegram, если он новый и не исключён.
+// Возвращает true, если пост опубликован
+func (p *Poster) Handle(ctx context.Context, post vk.Post) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.dryRunSent[post.ID] || p.ledger.Done(post.ID) {
+		return false, nil
+	}
+	if time.Unix(int64(post.Date), 0).Before(p.ledger.Since()) {
+		return false, nil
+	}
+
+	now := time.Now()
+	if reason := p.skipReason(post); reason != "" {
+		if p.DryRun {
+			p.dryRunSent[post.ID] = true
+			fmt.Printf("📭 [dry-run] пост %d пропущен: %s\n", post.ID, reason)
+			return false, nil
+		}
+		p.ledger.Skip(post.ID, reason, now)
+		return false, p.ledger.Save()
+	}
+
+	text, photos := Message(post), Photos(post)
+	if p.DryRun {
+		p.dryRunSent[post.ID] = true
+		fmt.Printf("📭 [dry-run] пост %d → %s, фото %d:\n%s\n", post.ID, p.ChatID, len(photos), text)
+		return true, nil
+	}
+
+	ids, err := p.send(ctx, text, photos)
+	if len(ids) == 0 {
+		p.ledger.Failed(post.ID, err, now)
+		if saveErr := p.ledger.Save(); saveErr != nil {
+			fmt.Println("❌ Сохранение журнала кросспостинга:", saveErr)
+		}
+		return false, err
+	}
+	// Фото уже в канале, даже если текст после них не ушёл: повтор задублировал бы альбом
+	p.ledger.Posted(post.ID, ids, now)
+	if saveErr := p.ledger.Save(); saveErr != nil {
+		return true, saveErr
+	}
+	return true, err
+}
+
+func (p *Poster) skipReason(post vk.Post) string {
+	if len(post.CopyHistory) > 0 {
+		return "репост"
+	}
+	if tag := Excluded(post.Text, p.ExcludeTags); tag != "" {
+		return "хештег " + tag
+	}
+	if Message(post) == "" && len(Photos(post)) == 0 {
+		return "нет текста и фото"
+	}
+	return ""
+}
+
+// send загружает фото и публикует пост. Если текст не помещается в подпись,
+// он уходит отдельным сообщением после фото
+func (p *Poster) send(ctx context.Context, text string, photoURLs []string) ([]int, error) {
+	if len(photoURLs) == 0 {
+		if utf8.RuneCountInString(text) > tg.MaxMessageLength {
+			return nil, fmt.Errorf("текст длиннее %d символов", tg.MaxMessageLength)
+		}
+		id, err := p.Telegram.SendMessage(ctx, p.ChatID, text)
+		if err != nil {
+			return nil, err
+		}
+		return []int{id}, nil
+	}
+
+	photos := []tg.InputFile{}
+	for i, url := range photoURLs {
+		photo, err := p.download(ctx, url, i)
+		if err != nil {
+			return nil, fmt.Errorf("загрузка фото: %v", err)
+		}
+		photos = append(photos, photo)
+	}
+
+	caption := text
+	if utf8.RuneCountInString(text) > tg.MaxCaptionLength {
+		caption = ""
+	}
+	ids, err := p.Telegram.SendPhotos(ctx, p.ChatID, photos, caption)
+	if err != nil {
+		return nil, err
+	}
+	if caption == "" && text != "" {
+		id, err := p.Telegram.SendMessage(ctx, p.ChatID, text)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (p *Poster) download(ctx context.Context, url string, i int) (tg.InputFile, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return tg.InputFile{}, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return tg.InputFile{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return tg.InputFile{}, fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return tg.InputFile{}, err
+	}
+
+	name := "photo" + strconv.Itoa(i) + path.Ext(req.URL.Path)
+	if path.Ext(req.URL.Path) == "" {
+		name += ".jpg"
+	}
+	return tg.InputFile{Name: name, Data: data}, nil
+}
@@ -0,0 +1,215 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"smm-helper/notify"
+	"smm-helper/report"
+)
+
+// Job — одна рассылка: период, расписание и получатели
+type Job struct {
+	Name       string
+	Period     string
+	Schedule   Schedule
+	Recipients []Recipient
+}
+
+// Recipient — почта и/или чат Telegram. Attach — формат вложения: csv или пусто
+type Recipient struct {
+	Email          string
+	TelegramChatID int64
+	Attach         string
+}
+
+// Scheduler раз в минуту сверяет время с расписаниями и рассылает дайджесты
+type Scheduler struct {
+	Jobs []Job
+	// Build собирает дайджест за последний завершённый период перед now
+	Build    func(period string, now time.Time) (report.Digest, error)
+	Mailer   *notify.Mailer
+	Telegram *notify.TelegramSender
+
+	mu sync.Mutex
+}
+
+// Run работает, пока не отменён ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, job := range s.Jobs {
+				if job.Schedule.Match(now) {
+					// Отправка в фоне: медленный SMTP не должен пропускать следующие минуты расписания
+					go func(job Job) {
+						if err := s.Send(ctx, job, now); err != nil {
+							fmt.Printf("❌ Дайджест %s: %v\n", job.Name, err)
+						}
+					}(job)
+				}
+			}
+		}
+	}
+}
+
+// Job ищет рассылку по имени
+func (s *Scheduler) Job(name string) (Job, bool) {
+	for _, job := range s.Jobs {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// Send собирает дайджест и отправляет всем получателям рассылки.
+// Ошибка одного получателя не мешает остальным, возвращается первая
+func (s *Scheduler) Send(ctx context.Context, job Job, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, err := s.Build(job.Period, now)
+	if err != nil {
+		return err
+	}
+
+	text := Text(d)
+	attachment := notify.Attachment{
+		Filename:    fmt.Sprintf("digest_%s_%s.csv", d.Period, d.From.Format("2006-01-02")),
+		ContentType: "text/csv; charset=utf-8",
+		Data:        CSV(d),
+	}
+
+	var firstErr error
+	fail := func(to string, err error) {
+		fmt.Printf("❌ Дайджест %s → %s: %v\n", job.Name, to, err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, r := range job.Recipients {
+		attachments := []notify.Attachment{}
+		if r.Attach == "csv" {
+			attachments = append(attachments, attachment)
+		}
+
+		if r.Email != "" {
+			if s.Mailer == nil {
+				fail(r.Email, fmt.Errorf("SMTP не настроен"))
+			} else if err := s.Mailer.Send([]string{r.Email}, d.Title(), text, attachments...); err != nil {
+				fail(r.Email, err)
+			}
+		}
+
+		if r.TelegramChatID != 0 {
+			to := strconv.FormatInt(r.TelegramChatID, 10)
+			if s.Telegram == nil {
+				fail(to, fmt.Errorf("бот Telegram не настроен"))
+				continue
+			}
+			if err := s.Telegram.Send(ctx, r.TelegramChatID, text); err != nil {
+				fail(to, err)
+				continue
+			}
+			for _, a := range attachments {
+				if err := s.Telegram.SendDocument(ctx, r.TelegramChatID, a, d.Title()); err != nil {
+					fail(to, err)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("📬 Дайджест %s отправлен (%d получателей)\n", job.Name, len(job.Recipients))
+	return firstErr
+}
+
+// Text — дайджест обычным текстом для письма и сообщения в Telegram
+func Text(d report.Digest) string {
+	var sb strings.Builder
+	sb.WriteString(d.Title() + "\n\n")
+
+	fmt.Fprintf(&sb, "Постов: %d (%s)\n", d.Count, signed(d.Change.Posts))
+	fmt.Fprintf(&sb, "Просмотры: %d (%s)\n", d.Totals.Views, signed(d.Change.Views))
+	fmt.Fprintf(&sb, "Лайки: %d (%s)\n", d.Totals.Likes, signed(d.Change.Likes))
+	fmt.Fprintf(&sb, "Репосты: %d (%s)\n", d.Totals.Reposts, signed(d.Change.Reposts))
+	fmt.Fprintf(&sb, "Комментарии: %d (%s)\n", d.Totals.Comments, signed(d.Change.Comments))
+
+	if len(d.TopPosts) > 0 {
+		sb.WriteString("\nЛучшие посты по просмотрам:\n")
+		for i, p := range d.TopPosts {
+			fmt.Fprintf(&sb, "%d. %s — 👁 %d ❤️ %d 🔁 %d\n   %s\n", i+1, p.Date.Format("02.01"), p.Views, p.Likes, p.Reposts, p.Link)
+		}
+	}
+
+	if len(d.Leaderboard) > 0 {
+		sb.WriteString("\nАктивность сотрудников:\n")
+		for i, e := range d.Leaderboard {
+			fmt.Fprintf(&sb, "%d. %s — ❤️ %d 🔁 %d\n", i+1, e.Employee.Name, e.Stats.Likes, e.Stats.Reposts)
+		}
+	}
+	return sb.String()
+}
+
+// CSV — итоги, лучшие посты и рейтинг сотрудников в одном файле (разделитель ;, как ждёт Excel)
+func CSV(d report.Digest) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff") // BOM, чтобы Excel распознал UTF-8
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	w.Write([]string{d.Title()})
+	w.Write([]string{"Показатель", "Текущий период", "Прошлый период", "Изменение, %"})
+	rows := []struct {
+		name        string
+		cur, prev   int
+		changeValue float64
+	}{
+		{"Постов", d.Count, d.PreviousCount, d.Change.Posts},
+		{"Просмотры", d.Totals.Views, d.Previous.Views, d.Change.Views},
+		{"Лайки", d.Totals.Likes, d.Previous.Likes, d.Change.Likes},
+		{"Репосты", d.Totals.Reposts, d.Previous.Reposts, d.Change.Reposts},
+		{"Комментарии", d.Totals.Comments, d.Previous.Comments, d.Change.Comments},
+	}
+	for _, r := range rows {
+		w.Write([]string{r.name, strconv.Itoa(r.cur), strconv.Itoa(r.prev), strconv.FormatFloat(r.changeValue, 'f', 1, 64)})
+	}
+
+	w.Write(nil)
+	w.Write([]string{"Дата", "Ссылка", "Просмотры", "Лайки", "Репосты", "Комментарии", "Текст"})
+	for _, p := range d.TopPosts {
+		w.Write([]string{
+			p.Date.Format("02.01.2006 15:04"), p.Link,
+			strconv.Itoa(p.Views), strconv.Itoa(p.Likes), strconv.Itoa(p.Reposts), strconv.Itoa(p.Comments),
+			p.ShortText(),
+		})
+	}
+
+	w.Write(nil)
+	w.Write([]string{"Сотрудник", "Лайки", "Репосты", "Итого"})
+	for _, e := range d.Leaderboard {
+		w.Write([]string{e.Employee.Name, strconv.Itoa(e.Stats.Likes), strconv.Itoa(e.Stats.Reposts), strconv.Itoa(e.Stats.Total)})
+	}
+
+	w.Flush()
+	return buf.Bytes()
+}
+
+func signed(v float64) string {
+	if v > 0 {
+		return fmt.Sprintf("+%.1f%%", v)
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
@@ -0,0 +1,65 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"smm-helper/report"
+	"smm-helper/vk"
+)
+
+func TestCSV(t *testing.T) {
+	d := report.Digest{
+		Period:        report.PeriodWeek,
+		From:          time.Date(2024, 2, 26, 0, 0, 0, 0, time.Local),
+		To:            time.Date(2024, 3, 3, 23, 59, 59, 0, time.Local),
+		Count:         3,
+		Totals:        report.Totals{Views: 300, Likes: 30, Reposts: 3, Comments: 6},
+		PreviousCount: 2,
+		Previous:      report.Totals{Views: 200, Likes: 40, Reposts: 3, Comments: 0},
+		Change:        report.Change{Posts: 50, Views: 50, Likes: -25},
+		TopPosts: []report.PostStat{
+			{Date: time.Date(2024, 2, 27, 10, 0, 0, 0, time.Local), Link: "https://vk.com/wall-1_5", Text: "Текст; с точкой с запятой", Views: 150, Likes: 20},
+		},
+		Leaderboard: []report.LeaderboardEntry{
+			{Employee: vk.Employee{Name: "Анна"}, Stats: vk.ActivityStats{Likes: 3, Reposts: 1, Total: 4}},
+		},
+	}
+
+	data := CSV(d)
+	if !bytes.HasPrefix(data, []byte("\ufeff")) {
+		t.Error("нет BOM в начале файла")
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.Comma = ';'
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	find := func(first string) []string {
+		for _, row := range rows {
+			if row[0] == first {
+				return row
+			}
+		}
+		t.Fatalf("нет строки %q", first)
+		return nil
+	}
+	if row := find("Просмотры"); row[1] != "300" || row[2] != "200" || row[3] != "50.0" {
+		t.Errorf("Просмотры: %v", row)
+	}
+	if row := find("Лайки"); row[3] != "-25.0" {
+		t.Errorf("Лайки: %v", row)
+	}
+	if row := find("27.02.2024 10:00"); row[1] != "https://vk.com/wall-1_5" || row[6] != "Текст; с точкой с запятой" {
+		t.Errorf("лучший пост: %v", row)
+	}
+	if row := find("Анна"); row[3] != "4" {
+		t.Errorf("рейтинг: %v", row)
+	}
+}
@@ -0,0 +1,113 @@
+package digest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule — расписание в формате cron из пяти полей: минута, час, день месяца, месяц, день недели.
+// Поддерживаются *, списки через запятую, диапазоны a-b и шаг /n. Воскресенье — 0 или 7.
+// Например "0 9 * * 1" — по понедельникам в 9:00, "0 9 1 * *" — первого числа месяца
+type Schedule struct {
+	expr   string
+	minute map[int]bool
+	hour   map[int]bool
+	dom    map[int]bool
+	month  map[int]bool
+	dow    map[int]bool
+	anyDOM bool
+	anyDOW bool
+}
+
+func ParseSchedule(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("расписание %q: нужно 5 полей", expr)
+	}
+
+	s := Schedule{expr: expr, anyDOM: fields[2] == "*", anyDOW: fields[4] == "*"}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return s, fmt.Errorf("расписание %q, минуты: %v", expr, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return s, fmt.Errorf("расписание %q, часы: %v", expr, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return s, fmt.Errorf("расписание %q, день месяца: %v", expr, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return s, fmt.Errorf("расписание %q, месяц: %v", expr, err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return s, fmt.Errorf("расписание %q, день недели: %v", expr, err)
+	}
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+	return s, nil
+}
+
+// Match — совпадает ли минута t с расписанием. Как в cron, если заданы и день месяца,
+// и день недели, достаточно совпадения одного из них
+func (s Schedule) Match(t time.Time) bool {
+	if !s.minute[t.Minute()] || !s.hour[t.Hour()] || !s.month[int(t.Month())] {
+		return false
+	}
+	dom, dow := s.dom[t.Day()], s.dow[int(t.Weekday())]
+	switch {
+	case s.anyDOM && s.anyDOW:
+		return true
+	case s.anyDOM:
+		return dow
+	case s.anyDOW:
+		return dom
+	}
+	return dom || dow
+}
+
+func (s Schedule) String() string {
+	return s.expr
+}
+
+func parseField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("неверный шаг %q", part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("неверное значение %q", part)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("неверное значение %q", part)
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("значение %q вне диапазона %d-%d", part, min, max)
+		}
+
+		for v := from; v <= to; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,77 @@
+package digest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 9 * *",
+		"0 9 * * * *",
+		"60 9 * * *",
+		"0 24 * * *",
+		"0 9 0 * *",
+		"0 9 * 13 *",
+		"0 9 * * 8",
+		"0 9-7 * * *",
+		"*/0 9 * * *",
+		"a 9 * * *",
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q): ожидалась ошибка", expr)
+		}
+	}
+}
+
+func TestScheduleMatch(t *testing.T) {
+	// 4 марта 2024 — понедельник
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"каждую минуту", "* * * * *", at(5, 13, 27), true},
+		{"по понедельникам в 9:00", "0 9 * * 1", at(4, 9, 0), true},
+		{"не понедельник", "0 9 * * 1", at(5, 9, 0), false},
+		{"не та минута", "0 9 * * 1", at(4, 9, 1), false},
+		{"список часов", "0 9,18 * * *", at(4, 18, 0), true},
+		{"список часов, мимо", "0 9,18 * * *", at(4, 12, 0), false},
+		{"диапазон дней недели", "30 10 * * 1-5", at(8, 10, 30), true},
+		{"диапазон, выходной", "30 10 * * 1-5", at(9, 10, 30), false},
+		{"шаг по минутам", "*/15 * * * *", at(4, 10, 45), true},
+		{"шаг по минутам, мимо", "*/15 * * * *", at(4, 10, 50), false},
+		{"шаг от начала", "5/20 * * * *", at(4, 10, 25), true},
+		{"шаг в диапазоне", "0 8-18/5 * * *", at(4, 13, 0), true},
+		{"шаг в диапазоне, мимо", "0 8-18/5 * * *", at(4, 14, 0), false},
+		{"воскресенье как 0", "0 9 * * 0", at(10, 9, 0), true},
+		{"воскресенье как 7", "0 9 * * 7", at(10, 9, 0), true},
+		{"первое число месяца", "0 9 1 * *", time.Date(2024, 4, 1, 9, 0, 0, 0, time.Local), true},
+		{"месяц не тот", "0 9 1 1 *", time.Date(2024, 4, 1, 9, 0, 0, 0, time.Local), false},
+		// День месяца и день недели заданы оба — достаточно одного совпадения
+		{"ИЛИ: совпал день месяца", "0 9 15 * 1", at(15, 9, 0), true},
+		{"ИЛИ: совпал день недели", "0 9 15 * 1", at(11, 9, 0), true},
+		{"ИЛИ: не совпало ничего", "0 9 15 * 1", at(12, 9, 0), false},
+		// Задан только один из них — второй не мешает
+		{"только день месяца", "0 9 15 * *", at(11, 9, 0), false},
+		{"только день недели", "0 9 * * 1", at(15, 9, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Match(tt.at); got != tt.want {
+				t.Errorf("%q.Match(%s) = %v, ожидалось %v", tt.expr, tt.at.Format("Mon 02.01 15:04"), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"smm-helper/config"
+	"smm-helper/digest"
+	"smm-helper/notify"
+	"smm-helper/report"
+	"smm-helper/vk"
+)
+
+// ========== ДАЙДЖЕСТЫ ПО РАСПИСАНИЮ ==========
+
+func initDigests() {
+	dc := cfg.Digests
+	digests = &digest.Scheduler{Build: buildDigest}
+
+	if dc.SMTP.Addr != "" {
+		digests.Mailer = &notify.Mailer{
+			Addr:     dc.SMTP.Addr,
+			From:     dc.SMTP.From,
+			Username: dc.SMTP.Username,
+			Password: dc.SMTP.Password,
+		}
+	}
+	if dc.Telegram.BotToken != "" {
+		digests.Telegram = notify.NewTelegramSender(dc.Telegram.BotToken, dc.Telegram.APIURL)
+	}
+
+	for _, s := range dc.Schedules {
+		schedule, err := digest.ParseSchedule(s.Cron)
+		if err != nil {
+			log.Fatalf("Дайджест %s: %v", s.Name, err)
+		}
+		if s.Period != report.PeriodWeek && s.Period != report.PeriodMonth {
+			log.Fatalf("Дайджест %s: период должен быть week или month", s.Name)
+		}
+
+		job := digest.Job{Name: s.Name, Period: s.Period, Schedule: schedule}
+		for _, r := range s.Recipients {
+			if r.Attach != "" && r.Attach != "csv" {
+				// Неверный формат не останавливает сервер: получатель остаётся, но без вложения
+				fmt.Printf("⚠️ Дайджест %s: вложение %q не поддерживается (только csv), %s получит дайджест без него\n",
+					s.Name, r.Attach, recipientTitle(r))
+				r.Attach = ""
+			}
+			job.Recipients = append(job.Recipients, digest.Recipient{
+				Email:          r.Email,
+				TelegramChatID: r.TelegramChatID,
+				Attach:         r.Attach,
+			})
+		}
+		digests.Jobs = append(digests.Jobs, job)
+	}
+}
+
+// recipientTitle — почта или чат получателя для сообщений в лог
+func recipientTitle(r config.DigestRecipient) string {
+	if r.Email != "" {
+		return r.Email
+	}
+	return fmt.Sprintf("чат %d", r.TelegramChatID)
+}
+
+func startDigests() {
+	if !cfg.Digests.Enabled || len(digests.Jobs) == 0 {
+		return
+	}
+	for _, job := range digests.Jobs {
+		fmt.Printf("📬 Дайджест %s (%s): %s, получателей %d\n", job.Name, job.Period, job.Schedule, len(job.Recipients))
+	}
+	go digests.Run(context.Background())
+}
+
+// buildDigest собирает дайджест за последний завершённый период (кэш на 30 минут)
+func buildDigest(period string, now time.Time) (report.Digest, error) {
+	from, to, prevFrom, _, err := report.PeriodBounds(period, now)
+	if err != nil {
+		return report.Digest{}, err
+	}
+
+	cacheKey := fmt.Sprintf("digest_%s_%s", period, from.Format("2006-01-02"))
+	if cached, found := dataCache.Get(cacheKey); found {
+		return cached.(report.Digest), nil
+	}
+
+	// Один проход по стене до начала прошлого периода, дальше делим посты по датам
+	posts := getPostsInRange(prevFrom, to)
+	current, previous := splitPosts(posts, from)
+
+	postIDs := []int{}
+	for _, p := range current {
+		postIDs = append(postIDs, p.ID)
+	}
+	likesMap, repostsMap := vkClient.GetLikesAndRepostsParallel(groupID, postIDs)
+	activity := report.BuildEmployeeActivity(groupID, current, employeeData, likesMap, repostsMap)
+
+	result := report.BuildDigest(groupID, period, from, to, current, previous, activity)
+	dataCache.Set(cacheKey, result, 30*time.Minute)
+	return result, nil
+}
+
+// splitPosts делит посты на вышедшие до from и начиная с from
+func splitPosts(posts []vk.Post, from time.Time) (current, previous []vk.Post) {
+	for _, p := range posts {
+		if time.Unix(int64(p.Date), 0).Before(from) {
+			previous = append(previous, p)
+		} else {
+			current = append(current, p)
+		}
+	}
+	return current, previous
+}
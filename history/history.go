@@ -0,0 +1,134 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"smm-helper/report"
+	"smm-helper/storage"
+)
+
+// Снимки метрик: частые в первые двое суток после публикации, потом раз в день
+const (
+	youngAge      = 48 * time.Hour
+	youngInterval = time.Hour
+	oldInterval   = 24 * time.Hour
+	retention     = 90 * 24 * time.Hour
+)
+
+type Snapshot struct {
+	At       time.Time `json:"at"`
+	Views    int       `json:"views"`
+	Likes    int       `json:"likes"`
+	Reposts  int       `json:"reposts"`
+	Comments int       `json:"comments"`
+}
+
+// ER — вовлечённость в процентах: (лайки + репосты + комментарии) / просмотры
+func (s Snapshot) ER() float64 {
+	if s.Views == 0 {
+		return 0
+	}
+	return float64(s.Likes+s.Reposts+s.Comments) / float64(s.Views) * 100
+}
+
+type Post struct {
+	ID        int        `json:"id"`
+	Date      time.Time  `json:"date"`
+	Link      string     `json:"link"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// At — первый снимок, сделанный не раньше чем через age после публикации.
+// Снимок позже age+window не считается — значения уже несопоставимы
+func (p Post) At(age, window time.Duration) (Snapshot, bool) {
+	for _, s := range p.Snapshots {
+		since := s.At.Sub(p.Date)
+		if since >= age && since <= age+window {
+			return s, true
+		}
+		if since > age+window {
+			break
+		}
+	}
+	return Snapshot{}, false
+}
+
+// Store — история метрик постов в JSON файле
+type Store struct {
+	path  string
+	posts map[int]*Post
+	mu    sync.RWMutex
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, posts: map[int]*Post{}}
+
+	var posts []*Post
+	if err := storage.ReadJSON(path, &posts); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	for _, p := range posts {
+		s.posts[p.ID] = p
+	}
+	return s, nil
+}
+
+// Record добавляет снимок метрик постов, если с прошлого снимка прошло достаточно времени.
+// Посты старше 90 дней удаляются
+func (s *Store) Record(at time.Time, stats []report.PostStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stat := range stats {
+		p, found := s.posts[stat.ID]
+		if !found {
+			p = &Post{ID: stat.ID, Date: stat.Date, Link: stat.Link}
+			s.posts[stat.ID] = p
+		}
+
+		interval := oldInterval
+		if at.Sub(p.Date) < youngAge {
+			interval = youngInterval
+		}
+		if n := len(p.Snapshots); n > 0 && at.Sub(p.Snapshots[n-1].At) < interval {
+			continue
+		}
+
+		p.Snapshots = append(p.Snapshots, Snapshot{
+			At:       at,
+			Views:    stat.Views,
+			Likes:    stat.Likes,
+			Reposts:  stat.Reposts,
+			Comments: stat.Comments,
+		})
+	}
+
+	for id, p := range s.posts {
+		if at.Sub(p.Date) > retention {
+			delete(s.posts, id)
+		}
+	}
+}
+
+// Posts — копия истории, от старых постов к новым
+func (s *Store) Posts() []Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]Post, 0, len(s.posts))
+	for _, p := range s.posts {
+		copied := *p
+		copied.Snapshots = append([]Snapshot(nil), p.Snapshots...)
+		posts = append(posts, copied)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].Date.Before(posts[j].Date) })
+	return posts
+}
+
+func (s *Store) Save() error {
+	posts := s.Posts()
+	return storage.WriteJSON(s.path, posts)
+}
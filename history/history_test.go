@@ -0,0 +1,137 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"smm-helper/report"
+)
+
+var published = time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local)
+
+func TestRecordIntervals(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := report.PostStat{ID: 1, Date: published, Link: "https://vk.com/wall-1_1"}
+
+	// Первые двое суток — не чаще раза в час, потом — раз в день
+	steps := []struct {
+		after    time.Duration
+		views    int
+		recorded bool
+	}{
+		{10 * time.Minute, 10, true},
+		{40 * time.Minute, 20, false},
+		{70 * time.Minute, 30, true},
+		{47 * time.Hour, 400, true},
+		{49 * time.Hour, 410, false},
+		{60 * time.Hour, 420, false},
+		{73 * time.Hour, 500, true},
+	}
+	want := 0
+	for _, step := range steps {
+		stat.Views = step.views
+		store.Record(published.Add(step.after), []report.PostStat{stat})
+		if step.recorded {
+			want++
+		}
+		posts := store.Posts()
+		if len(posts) != 1 || len(posts[0].Snapshots) != want {
+			t.Fatalf("через %v: снимков %d, ожидалось %d", step.after, len(posts[0].Snapshots), want)
+		}
+		if last := posts[0].Snapshots[want-1]; step.recorded && last.Views != step.views {
+			t.Errorf("через %v: последний снимок %d просмотров, ожидалось %d", step.after, last.Views, step.views)
+		}
+	}
+}
+
+func TestRecordRetention(t *testing.T) {
+	store, _ := NewStore(filepath.Join(t.TempDir(), "history.json"))
+	old := report.PostStat{ID: 1, Date: published}
+	fresh := report.PostStat{ID: 2, Date: published.AddDate(0, 0, 80)}
+
+	store.Record(published.Add(time.Hour), []report.PostStat{old})
+	store.Record(published.AddDate(0, 0, 91), []report.PostStat{fresh})
+
+	posts := store.Posts()
+	if len(posts) != 1 || posts[0].ID != 2 {
+		t.Errorf("после 90 дней осталось %+v, ожидался только пост 2", posts)
+	}
+}
+
+func TestStoreSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	store, _ := NewStore(path)
+	store.Record(published.Add(time.Hour), []report.PostStat{
+		{ID: 2, Date: published.Add(time.Hour), Views: 5},
+		{ID: 1, Date: published, Views: 50},
+	})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts := reloaded.Posts()
+	if len(posts) != 2 || posts[0].ID != 1 || posts[1].ID != 2 {
+		t.Fatalf("после перезагрузки %+v, ожидались посты 1 и 2 по дате", posts)
+	}
+	if posts[0].Snapshots[0].Views != 50 {
+		t.Errorf("снимок %+v", posts[0].Snapshots[0])
+	}
+
+	// Posts отдаёт копию: правка не меняет хранилище
+	posts[0].Snapshots[0].Views = 0
+	if reloaded.Posts()[0].Snapshots[0].Views != 50 {
+		t.Error("Posts() вернул снимки самого хранилища")
+	}
+}
+
+func TestPostAt(t *testing.T) {
+	p := Post{Date: published, Snapshots: []Snapshot{
+		{At: published.Add(time.Hour), Views: 10},
+		{At: published.Add(25 * time.Hour), Views: 100},
+		{At: published.Add(26 * time.Hour), Views: 110},
+		{At: published.Add(50 * time.Hour), Views: 200},
+	}}
+
+	tests := []struct {
+		name  string
+		age   time.Duration
+		views int
+		found bool
+	}{
+		{"первый снимок после age", 24 * time.Hour, 100, true},
+		{"снимок ровно в age", time.Hour, 10, true},
+		{"только снимок позже окна", 40 * time.Hour, 0, false},
+		{"снимков ещё нет", 60 * time.Hour, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, found := p.At(tt.age, 2*time.Hour)
+			if found != tt.found || s.Views != tt.views {
+				t.Errorf("At(%v) = %d, %v, ожидалось %d, %v", tt.age, s.Views, found, tt.views, tt.found)
+			}
+		})
+	}
+}
+
+func TestSnapshotER(t *testing.T) {
+	tests := []struct {
+		s    Snapshot
+		want float64
+	}{
+		{Snapshot{Views: 200, Likes: 10, Reposts: 4, Comments: 6}, 10},
+		{Snapshot{Views: 0, Likes: 3}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.s.ER(); got != tt.want {
+			t.Errorf("ER(%+v) = %v, ожидалось %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package kpi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"smm-helper/report"
+	"smm-helper/storage"
+	"smm-helper/vk"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusFail    = "fail"
+)
+
+// Rule — минимальная доля постов (в процентах), которую сотрудник должен лайкнуть и репостнуть.
+// 0 — требование не проверяется
+type Rule struct {
+	LikePercent   float64 `json:"like_percent"`
+	RepostPercent float64 `json:"repost_percent"`
+}
+
+// Rules выбираются по приоритету: сотрудник → роль → по умолчанию
+type Rules struct {
+	Default   Rule            `json:"default"`
+	Roles     map[string]Rule `json:"roles"`
+	Employees map[int]Rule    `json:"employees"`
+	// WarningPercent — порог выполнения, ниже которого статус fail, а не warning
+	WarningPercent float64 `json:"warning_percent"`
+}
+
+type Result struct {
+	Employee   vk.Employee `json:"employee"`
+	Rule       Rule        `json:"rule"`
+	Source     string      `json:"source"` // откуда взято правило: employee, role:<имя>, default
+	LikeRate   float64     `json:"like_rate"`
+	RepostRate float64     `json:"repost_rate"`
+	Compliance float64     `json:"compliance"`
+	Status     string      `json:"status"`
+}
+
+type Summary struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Posts   int       `json:"posts"`
+	Results []Result  `json:"results"`
+	Below   []Result  `json:"below"` // не выполнили цель, худшие первыми
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		Default:        Rule{LikePercent: 90, RepostPercent: 50},
+		Roles:          map[string]Rule{},
+		Employees:      map[int]Rule{},
+		WarningPercent: 80,
+	}
+}
+
+// RuleFor подбирает правило сотрудника. role — роль привязанного пользователя, может быть пустой
+func (r Rules) RuleFor(employeeID int, role string) (Rule, string) {
+	if rule, ok := r.Employees[employeeID]; ok {
+		return rule, "employee"
+	}
+	if rule, ok := r.Roles[role]; ok && role != "" {
+		return rule, "role:" + role
+	}
+	return r.Default, "default"
+}
+
+// Evaluate считает выполнение KPI по отчёту активности. roleOf возвращает роль сотрудника по VK ID
+func Evaluate(activity report.EmployeeActivityReport, rules Rules, roleOf func(int) string) Summary {
+	summary := Summary{Posts: len(activity.Posts), Results: []Result{}, Below: []Result{}}
+	if len(activity.Posts) > 0 {
+		// Посты идут от новых к старым
+		summary.To = activity.Posts[0].Date
+		summary.From = activity.Posts[len(activity.Posts)-1].Date
+	}
+
+	for _, e := range activity.Employees {
+		rule, source := rules.RuleFor(e.Employee.ID, roleOf(e.Employee.ID))
+		result := Result{Employee: e.Employee, Rule: rule, Source: source}
+
+		if summary.Posts > 0 {
+			result.LikeRate = percent(e.Stats.Likes, summary.Posts)
+			result.RepostRate = percent(e.Stats.Reposts, summary.Posts)
+		}
+		result.Compliance = compliance(result, rule)
+		result.Status = status(result.Compliance, rules.WarningPercent)
+
+		summary.Results = append(summary.Results, result)
+		if result.Status != StatusOK {
+			summary.Below = append(summary.Below, result)
+		}
+	}
+
+	sort.SliceStable(summary.Below, func(i, j int) bool {
+		return summary.Below[i].Compliance < summary.Below[j].Compliance
+	})
+	return summary
+}
+
+// ByEmployee — результаты по VK ID для шаблонов
+func (s Summary) ByEmployee() map[int]Result {
+	m := make(map[int]Result, len(s.Results))
+	for _, r := range s.Results {
+		m[r.Employee.ID] = r
+	}
+	return m
+}
+
+// compliance — среднее выполнение по активным требованиям, каждое не больше 100%
+func compliance(r Result, rule Rule) float64 {
+	parts := []float64{}
+	if rule.LikePercent > 0 {
+		parts = append(parts, math.Min(r.LikeRate/rule.LikePercent, 1))
+	}
+	if rule.RepostPercent > 0 {
+		parts = append(parts, math.Min(r.RepostRate/rule.RepostPercent, 1))
+	}
+	if len(parts) == 0 {
+		return 100
+	}
+
+	sum := 0.0
+	for _, p := range parts {
+		sum += p
+	}
+	return math.Round(sum/float64(len(parts))*1000) / 10
+}
+
+func status(compliance, warningPercent float64) string {
+	switch {
+	case compliance >= 100:
+		return StatusOK
+	case compliance >= warningPercent:
+		return StatusWarning
+	}
+	return StatusFail
+}
+
+func percent(n, total int) float64 {
+	return math.Round(float64(n)/float64(total)*1000) / 10
+}
+
+func (r Rule) Validate() error {
+	if r.LikePercent < 0 || r.LikePercent > 100 || r.RepostPercent < 0 || r.RepostPercent > 100 {
+		return fmt.Errorf("проценты должны быть от 0 до 100")
+	}
+	return nil
+}
+
+// Store хранит правила в JSON файле
+type Store struct {
+	path  string
+	rules Rules
+	mu    sync.RWMutex
+}
+
+func NewStore(path string) (*Store, error) {
+	rules := DefaultRules()
+	if err := storage.ReadJSON(path, &rules); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	if rules.Roles == nil {
+		rules.Roles = map[string]Rule{}
+	}
+	if rules.Employees == nil {
+		rules.Employees = map[int]Rule{}
+	}
+	return &Store{path: path, rules: rules}, nil
+}
+
+func (s *Store) Rules() Rules {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules
+}
+
+func (s *Store) Save(rules Rules) error {
+	for _, rule := range append([]Rule{rules.Default}, values(rules)...) {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	if rules.WarningPercent < 0 || rules.WarningPercent > 100 {
+		return fmt.Errorf("порог предупреждения должен быть от 0 до 100")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := storage.WriteJSON(s.path, rules); err != nil {
+		return err
+	}
+	s.rules = rules
+	return nil
+}
+
+func values(rules Rules) []Rule {
+	list := []Rule{}
+	for _, r := range rules.Roles {
+		list = append(list, r)
+	}
+	for _, r := range rules.Employees {
+		list = append(list, r)
+	}
+	return list
+}
+
+// StatusLabel — подпись статуса для страниц
+func (r Result) StatusLabel() string {
+	switch r.Status {
+	case StatusOK:
+		return "в норме"
+	case StatusWarning:
+		return "почти"
+	}
+	return "ниже цели"
+}
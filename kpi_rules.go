@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"smm-helper/auth"
+	"smm-helper/kpi"
+	"smm-helper/vk"
+)
+
+// ========== ЦЕЛИ ПО АКТИВНОСТИ (KPI) ==========
+
+// employeeRole — роль пользователя, привязанного к сотруднику VK. Без привязки — пусто
+func employeeRole(employeeID int) string {
+	if user, found := authManager.Users.FindByVKID(employeeID); found {
+		return user.Role
+	}
+	return ""
+}
+
+type kpiEmployeeRow struct {
+	Employee vk.Employee
+	Rule     kpi.Rule
+	Custom   bool
+}
+
+type kpiRoleRow struct {
+	Role   string
+	Rule   kpi.Rule
+	Custom bool
+}
+
+func kpiRulesHandler(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{}
+
+	if r.Method == "POST" {
+		rules, err := parseKPIRules(r)
+		if err == nil {
+			err = kpiStore.Save(rules)
+		}
+		if err != nil {
+			data["Error"] = err.Error()
+		} else {
+			data["Message"] = "Цели сохранены"
+		}
+	}
+
+	rules := kpiStore.Rules()
+
+	roles := []kpiRoleRow{}
+	for _, role := range []string{auth.RoleAdmin, auth.RoleManager, auth.RoleEmployee} {
+		rule, custom := rules.Roles[role]
+		roles = append(roles, kpiRoleRow{Role: role, Rule: rule, Custom: custom})
+	}
+
+	staff := []kpiEmployeeRow{}
+	for _, emp := range employeeData {
+		rule, custom := rules.Employees[emp.ID]
+		staff = append(staff, kpiEmployeeRow{Employee: emp, Rule: rule, Custom: custom})
+	}
+	sort.Slice(staff, func(i, j int) bool { return staff[i].Employee.Name < staff[j].Employee.Name })
+
+	data["Rules"] = rules
+	data["Roles"] = roles
+	data["Employees"] = staff
+
+	tmpl := template.Must(template.ParseFiles("templates/kpi.html"))
+	tmpl.Execute(w, data)
+}
+
+// parseKPIRules собирает правила из формы. Пустые поля роли или сотрудника — наследовать
+func parseKPIRules(r *http.Request) (kpi.Rules, error) {
+	rules := kpi.Rules{Roles: map[string]kpi.Rule{}, Employees: map[int]kpi.Rule{}}
+
+	var err error
+	if rules.Default, _, err = parseKPIRule(r, "default"); err != nil {
+		return rules, err
+	}
+	if rules.WarningPercent, err = parsePercent(r.FormValue("warning_percent")); err != nil {
+		return rules, err
+	}
+
+	for _, role := range []string{auth.RoleAdmin, auth.RoleManager, auth.RoleEmployee} {
+		rule, set, err := parseKPIRule(r, "role_"+role)
+		if err != nil {
+			return rules, err
+		}
+		if set {
+			rules.Roles[role] = rule
+		}
+	}
+
+	for id := range employeeData {
+		rule, set, err := parseKPIRule(r, "emp_"+strconv.Itoa(id))
+		if err != nil {
+			return rules, err
+		}
+		if set {
+			rules.Employees[id] = rule
+		}
+	}
+	return rules, nil
+}
+
+// parseKPIRule читает пару полей <prefix>_like и <prefix>_repost. set=false, если оба пустые
+func parseKPIRule(r *http.Request, prefix string) (rule kpi.Rule, set bool, err error) {
+	like := strings.TrimSpace(r.FormValue(prefix + "_like"))
+	repost := strings.TrimSpace(r.FormValue(prefix + "_repost"))
+	if like == "" && repost == "" {
+		return rule, false, nil
+	}
+
+	if rule.LikePercent, err = parsePercent(like); err != nil {
+		return rule, false, err
+	}
+	if rule.RepostPercent, err = parsePercent(repost); err != nil {
+		return rule, false, err
+	}
+	return rule, true, nil
+}
+
+func parsePercent(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	p, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("неверное значение процента: %s", value)
+	}
+	return p, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"smm-helper/auth"
+	"smm-helper/vk"
+)
+
+// ========== ВХОД И ПОЛЬЗОВАТЕЛИ ==========
+
+const oauthStateCookie = "smm_oauth_state"
+
+// Ошибки входа через VK передаются в /login кодом, чтобы в URL нельзя было подставить свой текст
+var loginErrors = map[string]string{
+	"vk_state":    "Сессия входа через VK устарела, попробуйте ещё раз",
+	"vk_failed":   "Не удалось войти через VK",
+	"vk_unlinked": "Этот аккаунт VK не привязан к пользователю",
+}
+
+func initAuth() {
+	users, err := auth.NewUserStore(cfg.Auth.UsersFile)
+	if err != nil {
+		log.Fatal("Ошибка загрузки пользователей: ", err)
+	}
+
+	// Первый запуск: создаём администратора. Пароль берём из SMM_ADMIN_PASSWORD или генерируем
+	if users.Len() == 0 {
+		password := os.Getenv("SMM_ADMIN_PASSWORD")
+		if password == "" {
+			password = auth.RandomToken()[:12]
+			fmt.Printf("🔑 Создан администратор admin с паролем %s — смените его на /users\n", password)
+		}
+		if err := users.Save(auth.User{Username: "admin", Name: "Администратор", Role: auth.RoleAdmin}, password); err != nil {
+			log.Fatal("Ошибка создания администратора: ", err)
+		}
+	}
+
+	secret := cfg.Auth.LinkSecret
+	if secret == "" {
+		secret = auth.RandomToken()
+		fmt.Println("⚠️  auth.link_secret не задан: персональные ссылки сотрудников перестанут работать после перезапуска")
+	}
+	linkSigner = auth.NewLinkSigner(secret)
+
+	sessions := auth.NewSessionStore(time.Duration(cfg.Auth.SessionTTLHours) * time.Hour)
+	authManager = auth.NewManager(users, sessions)
+
+	if cfg.Auth.VKID.ClientID != "" {
+		authManager.Provider = auth.NewVKIDProvider(cfg.Auth.VKID.ClientID, cfg.Auth.VKID.ClientSecret, cfg.Auth.VKID.RedirectURL)
+	}
+
+	// API отвечает JSON, а не редиректом на страницу входа
+	authManager.OnDenied = func(w http.ResponseWriter, r *http.Request, status int) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if status == http.StatusUnauthorized {
+				writeAPIError(w, status, "unauthorized", "требуется вход")
+			} else {
+				writeAPIError(w, status, "forbidden", "недостаточно прав")
+			}
+			return
+		}
+		auth.DefaultDeny(w, r, status)
+	}
+}
+
+// safeNext не даёт увести пользователя на чужой сайт после входа
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		return "/"
+	}
+	return next
+}
+
+func loginHandler(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Next":      safeNext(r.FormValue("next")),
+		"VKEnabled": authManager.Provider != nil,
+		"Error":     loginErrors[r.URL.Query().Get("error")],
+	}
+
+	if r.Method == "POST" {
+		user, err := authManager.Users.Authenticate(r.FormValue("username"), r.FormValue("password"))
+		if err == nil {
+			authManager.Login(w, r, user)
+			fmt.Printf("🔓 Вход: %s (%s)\n", user.Username, user.Role)
+			http.Redirect(w, r, safeNext(r.FormValue("next")), http.StatusSeeOther)
+			return
+		}
+		data["Error"] = err.Error()
+		data["Username"] = r.FormValue("username")
+	}
+
+	tmpl := template.Must(template.ParseFiles("templates/login.html"))
+	tmpl.Execute(w, data)
+}
+
+func logoutHandler(w http.ResponseWriter, r *http.Request) {
+	authManager.Logout(w, r)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func vkLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if authManager.Provider == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := auth.RandomToken()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/auth/vk",
+		MaxAge:   600,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authManager.Provider.AuthURL(state), http.StatusSeeOther)
+}
+
+func vkCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if authManager.Provider == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != r.URL.Query().Get("state") {
+		http.Redirect(w, r, "/login?error=vk_state", http.StatusSeeOther)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Value: "", Path: "/auth/vk", MaxAge: -1})
+
+	vkID, err := authManager.Provider.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		fmt.Println("❌ VK ID:", err)
+		http.Redirect(w, r, "/login?error=vk_failed", http.StatusSeeOther)
+		return
+	}
+
+	user, found := authManager.Users.FindByVKID(vkID)
+	if !found {
+		http.Redirect(w, r, "/login?error=vk_unlinked", http.StatusSeeOther)
+		return
+	}
+
+	authManager.Login(w, r, user)
+	fmt.Printf("🔓 Вход через VK: %s (%s)\n", user.Username, user.Role)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func usersHandler(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{}
+
+	if r.Method == "POST" {
+		vkID, _ := strconv.Atoi(r.FormValue("vk_id"))
+		user := auth.User{
+			Username: strings.TrimSpace(r.FormValue("username")),
+			Name:     strings.TrimSpace(r.FormValue("name")),
+			Role:     r.FormValue("role"),
+			VKID:     vkID,
+		}
+		password := r.FormValue("password")
+
+		if _, exists := authManager.Users.Get(user.Username); !exists && password == "" && user.VKID == 0 {
+			data["Error"] = "Для нового пользователя укажите пароль или VK ID"
+		} else if err := authManager.Users.Save(user, password); err != nil {
+			data["Error"] = err.Error()
+		} else {
+			// Роль могла измениться — текущие сессии пользователя больше не действительны
+			authManager.Sessions.DeleteUser(user.Username)
+			data["Message"] = "Пользователь " + user.Username + " сохранён"
+		}
+	}
+
+	staff := []vk.Employee{}
+	for _, emp := range employeeData {
+		staff = append(staff, emp)
+	}
+	sort.Slice(staff, func(i, j int) bool { return staff[i].Name < staff[j].Name })
+
+	data["Users"] = authManager.Users.List()
+	data["Employees"] = staff
+	data["Roles"] = []string{auth.RoleAdmin, auth.RoleManager, auth.RoleEmployee}
+
+	tmpl := template.Must(template.ParseFiles("templates/users.html"))
+	tmpl.Execute(w, data)
+}
+
+func deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	username := r.FormValue("username")
+	if session, _ := auth.FromContext(r.Context()); session.Username == username {
+		http.Error(w, "Нельзя удалить самого себя", http.StatusBadRequest)
+		return
+	}
+
+	if err := authManager.Users.Delete(username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	authManager.Sessions.DeleteUser(username)
+	http.Redirect(w, r, "/users", http.StatusSeeOther)
+}
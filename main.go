@@ -1,15 +1,32 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"smm-helper/anomaly"
+	"smm-helper/auth"
 	"smm-helper/cache"
+	"smm-helper/chart"
+	"smm-helper/config"
+	"smm-helper/crosspost"
+	"smm-helper/digest"
+	"smm-helper/history"
+	"smm-helper/kpi"
+	"smm-helper/plan"
+	"smm-helper/post"
+	"smm-helper/reminder"
+	"smm-helper/report"
+	"smm-helper/tg"
+	"smm-helper/tg/mtproto"
 	"smm-helper/vk"
 
 	"github.com/gorilla/handlers"
@@ -22,11 +39,24 @@ const (
 )
 
 var (
-	vkClient  *vk.Client
-	dataCache *cache.Cache
-	groupID   int
-	groupName string
-	employees = []string{
+	cfg         *config.Config
+	vkClient    *vk.Client
+	dataCache   *cache.Cache
+	authManager *auth.Manager
+	linkSigner  *auth.LinkSigner
+	kpiStore    *kpi.Store
+	reminderBot *reminder.Bot
+	digests     *digest.Scheduler
+	postHistory *history.Store
+	alertLog    *anomaly.Log
+	tgClient    *tg.Client
+	tgPublic    *tg.SimpleClient
+	tgMTProto   *mtproto.Client
+	tgEmployees []tg.Employee
+	crossPoster *crosspost.Poster
+	groupID     int
+	groupName   string
+	employees   = []string{
 		"kozhan_vi", "id50311017", "idlinkinpark", "id138790792",
 		"starostaandrey", "id206710878", "id313673888",
 		"fishka074", "iamkatekey", "yara.timofeeva",
@@ -35,8 +65,27 @@ var (
 )
 
 func init() {
+	var err error
+	cfg, err = config.Load("config.json")
+	if err != nil {
+		log.Fatal("Ошибка чтения config.json: ", err)
+	}
+
 	vkClient = vk.NewClient(VK_ACCESS_TOKEN)
 	dataCache = cache.NewCache()
+	initAuth()
+
+	kpiStore, err = kpi.NewStore(cfg.KPI.RulesFile)
+	if err != nil {
+		log.Fatal("Ошибка загрузки KPI: ", err)
+	}
+	initReminders()
+	initDigests()
+	initAlerts()
+	initTelegram()
+	initCrosspost()
+	initVKCallback()
+	initContentPlanner()
 
 	group, err := vkClient.GetGroupByDomain(GROUP_DOMAIN)
 	if err != nil {
@@ -44,6 +93,8 @@ func init() {
 	}
 	groupID = -group.ID
 	groupName = group.Name
+	// Контент-план отправляется на стену сообщества, поэтому только после groupID
+	planPublisher = plan.NewPublisher(planStore, vkClient, groupID)
 
 	employeeData, err = vkClient.GetEmployees(employees)
 	if err != nil {
@@ -53,21 +104,78 @@ func init() {
 	fmt.Printf("✅ Группа: %s (ID: %d)\n", groupName, group.ID)
 	fmt.Printf("✅ Сотрудников: %d\n", len(employeeData))
 	fmt.Println("✅ Кэширование включено (5 минут)")
+	fmt.Printf("✅ Пользователей: %d\n", authManager.Users.Len())
 }
 
 func main() {
 	r := mux.NewRouter()
+	r.Use(authManager.Middleware)
+
+	// Вход и выход доступны без сессии
+	r.HandleFunc("/login", loginHandler).Methods("GET", "POST")
+	r.HandleFunc("/logout", logoutHandler).Methods("GET", "POST")
+	r.HandleFunc("/auth/vk", vkLoginHandler).Methods("GET")
+	r.HandleFunc("/auth/vk/callback", vkCallbackHandler).Methods("GET")
+	r.HandleFunc("/me/{id:[0-9]+}/{sig}", signedMeHandler).Methods("GET")
+	r.HandleFunc("/me/{id:[0-9]+}/{sig}/reminders", signedMeRemindersHandler).Methods("POST")
+
+	// Вебхук Telegram проверяет секрет сам
+	r.HandleFunc("/tg/webhook", tgClient.WebhookHandler(cfg.Telegram.WebhookSecret)).Methods("POST")
+	// Callback API VK — тоже, по секретному ключу в теле запроса
+	if vc := cfg.VKCallback; vc.Enabled {
+		r.HandleFunc("/vk/callback", vk.CallbackHandler(-groupID, vc.Confirmation, vc.Secret, handleVKEvent)).Methods("POST")
+		fmt.Println("📨 VK Callback API: /vk/callback")
+	}
 
-	// VK роуты
-	r.HandleFunc("/", indexHandler).Methods("GET")
-	r.HandleFunc("/employee_activity", employeeActivityHandler).Methods("GET", "POST")
-	r.HandleFunc("/posts_analysis", postsAnalysisHandler).Methods("GET", "POST")
-	r.HandleFunc("/date_range", dateRangeHandler).Methods("GET", "POST")
-	r.HandleFunc("/clear_cache", clearCacheHandler).Methods("GET")
-
-	// TELEGRAM роуты ← ДОБАВЬ ЭТО
-	r.HandleFunc("/tg", tgIndexHandler).Methods("GET")
-	r.HandleFunc("/tg/posts_analysis", tgPostsAnalysisHandler).Methods("GET")
+	// JSON API
+	registerAPIRoutes(r)
+
+	// Все роли: сотрудник видит только свою активность
+	everyone := r.NewRoute().Subrouter()
+	everyone.Use(authManager.Require(auth.RoleAdmin, auth.RoleManager, auth.RoleEmployee))
+	everyone.HandleFunc("/", indexHandler).Methods("GET")
+	everyone.HandleFunc("/employee_activity", employeeActivityHandler).Methods("GET", "POST")
+	everyone.HandleFunc("/me", meHandler).Methods("GET")
+	everyone.HandleFunc("/me/reminders", meRemindersHandler).Methods("POST")
+
+	// VK отчёты
+	reports := r.NewRoute().Subrouter()
+	reports.Use(authManager.Require(auth.RoleAdmin, auth.RoleManager))
+	reports.HandleFunc("/posts_analysis", postsAnalysisHandler).Methods("GET", "POST")
+	reports.HandleFunc("/date_range", dateRangeHandler).Methods("GET", "POST")
+	reports.HandleFunc("/overview", overviewHandler).Methods("GET", "POST")
+	reports.HandleFunc("/compose", composeHandler).Methods("GET", "POST")
+	reports.HandleFunc("/compose/delete", deletePostponedHandler).Methods("POST")
+	reports.HandleFunc("/content_plan", contentPlanHandler).Methods("GET", "POST")
+	reports.HandleFunc("/content_plan/save", savePlanHandler).Methods("POST")
+	reports.HandleFunc("/content_plan/{id:[0-9]+}", planViewHandler).Methods("GET")
+	reports.HandleFunc("/content_plan/{id:[0-9]+}/delete", deletePlanHandler).Methods("POST")
+	reports.HandleFunc("/content_plan/{id:[0-9]+}/publish", planPublishHandler).Methods("POST")
+	reports.HandleFunc("/content_plan/{id:[0-9]+}/items/{n:[0-9]+}", planItemHandler).Methods("POST")
+	reports.HandleFunc("/content_plan/{id:[0-9]+}/items/{n:[0-9]+}/regenerate", planRegenerateHandler).Methods("POST")
+	reports.HandleFunc("/content_plan/{id:[0-9]+}/items/{n:[0-9]+}/history", planHistoryHandler).Methods("GET")
+
+	// TELEGRAM роуты
+	reports.HandleFunc("/tg", tgIndexHandler).Methods("GET")
+	reports.HandleFunc("/tg/posts_analysis", tgPostsAnalysisHandler).Methods("GET", "POST")
+	reports.HandleFunc("/tg/date_range", tgDateRangeHandler).Methods("GET", "POST")
+	reports.HandleFunc("/tg/reactions", tgReactionsHandler).Methods("GET", "POST")
+	reports.HandleFunc("/tg/employee_activity", tgEmployeeActivityHandler).Methods("GET", "POST")
+
+	// Администрирование
+	admin := r.NewRoute().Subrouter()
+	admin.Use(authManager.Require(auth.RoleAdmin))
+	admin.HandleFunc("/clear_cache", clearCacheHandler).Methods("GET")
+	admin.HandleFunc("/users", usersHandler).Methods("GET", "POST")
+	admin.HandleFunc("/users/delete", deleteUserHandler).Methods("POST")
+	admin.HandleFunc("/kpi", kpiRulesHandler).Methods("GET", "POST")
+
+	startReminders()
+	startDigests()
+	startAlerts()
+	startTelegram()
+	startCrosspost()
+	startVKLongPoll()
 
 	compressed := handlers.CompressHandler(r)
 	logged := handlers.LoggingHandler(os.Stdout, compressed)
@@ -75,17 +183,42 @@ func main() {
 	fmt.Println("🚀 Сервер запущен на http://localhost:8080")
 	fmt.Println("📱 VK: http://localhost:8080")
 	fmt.Println("✈️  Telegram: http://localhost:8080/tg")
+	fmt.Println("🔌 API: http://localhost:8080/api/v1/openapi.json")
 	log.Fatal(http.ListenAndServe(":8080", logged))
 }
 
 func indexHandler(w http.ResponseWriter, r *http.Request) {
+	session, _ := auth.FromContext(r.Context())
+
+	// Аномалии за последние сутки — только тем, кто видит отчёты
+	alerts := []anomaly.Alert{}
+	if session.CanViewReports() {
+		for _, a := range alertLog.Recent(5) {
+			if time.Since(a.At) < 24*time.Hour {
+				alerts = append(alerts, a)
+			}
+		}
+	}
+
 	tmpl := template.Must(template.ParseFiles("templates/index.html"))
-	tmpl.Execute(w, map[string]string{
+	tmpl.Execute(w, map[string]interface{}{
 		"GroupName": groupName,
 		"GroupURL":  "https://vk.com/" + GROUP_DOMAIN,
+		"Session":   session,
+		"Alerts":    alerts,
 	})
 }
 
+type employeeActivityPage struct {
+	N      int
+	Report report.EmployeeActivityReport
+	Chart  template.HTML
+	// Подписанные ссылки на личные страницы — только для админа и менеджера
+	PersonalLinks map[int]string
+	KPI           map[int]kpi.Result
+	KPISummary    kpi.Summary
+}
+
 func employeeActivityHandler(w http.ResponseWriter, r *http.Request) {
 	count := 30
 	if r.Method == "POST" {
@@ -95,120 +228,197 @@ func employeeActivityHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	cacheKey := fmt.Sprintf("employee_activity_%d", count)
+	activity, err := getEmployeeActivity(count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Сотрудник видит только свою строку
+	session, _ := auth.FromContext(r.Context())
+	if session.Role == auth.RoleEmployee {
+		activity = activity.Only(session.VKID)
+	}
+	summary := kpi.Evaluate(activity, kpiStore.Rules(), employeeRole)
+
+	links := map[int]string{}
+	if session.CanViewReports() {
+		for _, e := range activity.Employees {
+			links[e.Employee.ID] = personalLink(e.Employee.ID)
+		}
+	}
+
+	names := []string{}
+	likesSeries := chart.Series{Name: "Лайки", Color: chart.ColorPink}
+	repostsSeries := chart.Series{Name: "Репосты", Color: chart.ColorGreen}
+	for _, e := range activity.Employees {
+		names = append(names, e.Employee.Name)
+		likesSeries.Values = append(likesSeries.Values, e.Stats.Likes)
+		repostsSeries.Values = append(repostsSeries.Values, e.Stats.Reposts)
+	}
+
+	tmpl := template.Must(template.ParseFiles("templates/employee_activity.html"))
+	tmpl.Execute(w, employeeActivityPage{
+		N:             count,
+		Report:        activity,
+		Chart:         chart.StackedBarChart(names, []chart.Series{likesSeries, repostsSeries}),
+		PersonalLinks: links,
+		KPI:           summary.ByEmployee(),
+		KPISummary:    summary,
+	})
+}
+
+// getEmployeeActivity строит отчёт по последним постам и лайкам/репостам к ним.
+// Стена загружается из VK не чаще раза в wallTTL, между загрузками её обновляют события VK
+func getEmployeeActivity(count int) (report.EmployeeActivityReport, error) {
+	posts, likesMap, repostsMap, err := vkWall.Get(count)
+	if err != nil {
+		return report.EmployeeActivityReport{}, err
+	}
+	return report.BuildEmployeeActivity(groupID, posts, employeeData, likesMap, repostsMap), nil
+}
+
+type postsAnalysisPage struct {
+	N      int
+	Report report.PostStats
+	Chart  template.HTML
+}
+
+func postsAnalysisHandler(w http.ResponseWriter, r *http.Request) {
+	count := 30
+	if r.Method == "POST" {
+		c, _ := strconv.Atoi(r.FormValue("n"))
+		if c > 0 && c <= 100 {
+			count = c
+		}
+	}
+
+	cacheKey := fmt.Sprintf("posts_analysis_%d", count)
 
 	if cached, found := dataCache.Get(cacheKey); found {
-		fmt.Printf("📦 Из кэша (%d постов)\n", count)
-		tmpl := template.Must(template.ParseFiles("templates/employee_activity.html"))
+		tmpl := template.Must(template.ParseFiles("templates/posts_analysis.html"))
 		tmpl.Execute(w, cached)
 		return
 	}
 
-	fmt.Printf("🔄 Загрузка с VK (%d постов)...\n", count)
-	startTime := time.Now()
-
 	posts, err := vkClient.GetWallPosts(groupID, count)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	postIDs := []int{}
-	for _, post := range posts {
-		postIDs = append(postIDs, post.ID)
+	stats := report.BuildPostStats(groupID, posts)
+
+	// Посты приходят от новых к старым, на графике — слева направо по времени
+	bars := []chart.Bar{}
+	for i := len(stats.Posts) - 1; i >= 0; i-- {
+		p := stats.Posts[i]
+		bars = append(bars, chart.Bar{Label: p.Date.Format("02.01"), Value: p.Views, Link: p.Link})
 	}
 
-	likesMap, repostsMap := vkClient.GetLikesAndRepostsParallel(groupID, postIDs)
+	result := postsAnalysisPage{
+		N:      count,
+		Report: stats,
+		Chart:  chart.BarChart(bars, chart.ColorBlue),
+	}
 
-	activity := make(map[int][]string)
-	postDates := []string{}
-	postLinks := []string{}
-	totals := make(map[int]vk.ActivityStats)
+	dataCache.Set(cacheKey, result, 30*time.Minute)
 
-	for _, post := range posts {
-		postDate := time.Unix(int64(post.Date), 0).Format("02.01")
-		postLink := fmt.Sprintf("https://vk.com/wall%d_%d", groupID, post.ID)
-		postDates = append(postDates, postDate)
-		postLinks = append(postLinks, postLink)
+	tmpl := template.Must(template.ParseFiles("templates/posts_analysis.html"))
+	tmpl.Execute(w, result)
+}
 
-		likes := likesMap[post.ID]
-		reposts := repostsMap[post.ID]
+type dateRangePage struct {
+	Error  string
+	Report *report.RangeReport
+	Chart  template.HTML
+}
 
-		likeSet := make(map[int]bool)
-		for _, id := range likes {
-			likeSet[id] = true
-		}
-		repostSet := make(map[int]bool)
-		for _, id := range reposts {
-			repostSet[id] = true
-		}
+func dateRangeHandler(w http.ResponseWriter, r *http.Request) {
+	page := dateRangePage{}
 
-		for empID := range employeeData {
-			hasLike := likeSet[empID]
-			hasRepost := repostSet[empID]
-
-			var symbol string
-			if hasLike && hasRepost {
-				symbol = "❤️🔁"
-			} else if hasLike {
-				symbol = "❤️"
-			} else if hasRepost {
-				symbol = "🔁"
-			} else {
-				symbol = "➖"
-			}
+	if r.Method == "POST" {
+		startDate, err1 := time.ParseInLocation("02.01.2006", r.FormValue("date_from"), time.Local)
+		endDate, err2 := time.ParseInLocation("02.01.2006", r.FormValue("date_to"), time.Local)
 
-			activity[empID] = append(activity[empID], symbol)
+		if err1 != nil || err2 != nil {
+			page.Error = "Неверный формат даты (ДД.ММ.ГГГГ)"
+		} else {
+			endDate = endDate.Add(23*time.Hour + 59*time.Minute)
+			rangeReport := report.BuildRangeReport(groupID, startDate, endDate, getPostsInRange(startDate, endDate))
 
-			stats := totals[empID]
-			if hasLike {
-				stats.Likes++
-			}
-			if hasRepost {
-				stats.Reposts++
+			// Суммарные просмотры по дням, включая дни без постов
+			points := []chart.Point{}
+			for _, day := range rangeReport.Daily {
+				points = append(points, chart.Point{Label: day.Date.Format("02.01"), Value: day.Totals.Views})
 			}
-			stats.Total = stats.Likes + stats.Reposts
-			totals[empID] = stats
+
+			page.Report = &rangeReport
+			page.Chart = chart.LineChart(points, chart.ColorBlue)
 		}
 	}
 
-	type empData struct {
-		Employee vk.Employee
-		Activity []string
-		Stats    vk.ActivityStats
-	}
-	var data []empData
-	for empID, emp := range employeeData {
-		data = append(data, empData{
-			Employee: emp,
-			Activity: activity[empID],
-			Stats:    totals[empID],
-		})
-	}
+	tmpl := template.Must(template.ParseFiles("templates/date_range.html"))
+	tmpl.Execute(w, page)
+}
 
-	for i := 0; i < len(data)-1; i++ {
-		for j := 0; j < len(data)-i-1; j++ {
-			if data[j].Stats.Total < data[j+1].Stats.Total {
-				data[j], data[j+1] = data[j+1], data[j]
+// getPostsInRange листает стену от новых постов к старым, пока не выйдет за начало периода
+func getPostsInRange(startDate, endDate time.Time) []vk.Post {
+	allPosts := []vk.Post{}
+	offset := 0
+	for {
+		posts, err := vkClient.GetWallPostsWithOffset(groupID, 100, offset)
+		if err != nil || len(posts) == 0 {
+			break
+		}
+
+		shouldBreak := false
+		for _, post := range posts {
+			postTime := time.Unix(int64(post.Date), 0)
+			if postTime.After(endDate) {
+				continue
+			}
+			if postTime.Before(startDate) {
+				shouldBreak = true
+				break
 			}
+			allPosts = append(allPosts, post)
 		}
-	}
 
-	result := map[string]interface{}{
-		"Data":      data,
-		"PostDates": postDates,
-		"PostLinks": postLinks,
-		"N":         count,
+		if shouldBreak {
+			break
+		}
+		offset += 100
 	}
+	return allPosts
+}
+
+func clearCacheHandler(w http.ResponseWriter, r *http.Request) {
+	dataCache.Clear()
+	vkWall.Reset()
+	tgPublic.ClearCache()
+	fmt.Println("🗑️ Кэш очищен")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
 
-	dataCache.Set(cacheKey, result, 5*time.Minute)
-	fmt.Printf("✅ Загружено за %v\n", time.Since(startTime))
+// ========== TELEGRAM ФУНКЦИОНАЛ ==========
 
-	tmpl := template.Must(template.ParseFiles("templates/employee_activity.html"))
-	tmpl.Execute(w, result)
+func tgIndexHandler(w http.ResponseWriter, r *http.Request) {
+	tmpl := template.Must(template.ParseFiles("templates/tg_index.html"))
+	tmpl.Execute(w, map[string]string{
+		"ChannelName": "Kait.20 Telegram",
+		"ChannelURL":  "https://t.me/kait_20_official",
+	})
 }
 
-func postsAnalysisHandler(w http.ResponseWriter, r *http.Request) {
+type tgPostsAnalysisPage struct {
+	N      int
+	Report report.TGPostStats
+	Chart  template.HTML
+	Source string
+}
+
+func tgPostsAnalysisHandler(w http.ResponseWriter, r *http.Request) {
 	count := 30
 	if r.Method == "POST" {
 		c, _ := strconv.Atoi(r.FormValue("n"))
@@ -217,209 +427,298 @@ func postsAnalysisHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	cacheKey := fmt.Sprintf("posts_analysis_%d", count)
+	cacheKey := fmt.Sprintf("tg_posts_analysis_%d", count)
 
 	if cached, found := dataCache.Get(cacheKey); found {
-		tmpl := template.Must(template.ParseFiles("templates/posts_analysis.html"))
+		tmpl := template.Must(template.ParseFiles("templates/tg_posts_analysis.html"))
 		tmpl.Execute(w, cached)
 		return
 	}
 
-	posts, err := vkClient.GetWallPosts(groupID, count)
+	posts, source, err := getTGPosts(count)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	type postStat struct {
-		Date     string
-		Link     string
-		Text     string
-		Views    int
-		Likes    int
-		Reposts  int
-		Comments int
+	stats := report.BuildTGPostStats(cfg.Telegram.ChannelID, posts)
+
+	bars := []chart.Bar{}
+	for i := len(stats.Posts) - 1; i >= 0; i-- {
+		p := stats.Posts[i]
+		bars = append(bars, chart.Bar{Label: p.Date.Format("02.01"), Value: p.Views, Link: p.Link})
+	}
+
+	result := tgPostsAnalysisPage{
+		N:      count,
+		Report: stats,
+		Chart:  chart.BarChart(bars, chart.ColorBlue),
+		Source: source,
+	}
+
+	dataCache.Set(cacheKey, result, 30*time.Minute)
+
+	tmpl := template.Must(template.ParseFiles("templates/tg_posts_analysis.html"))
+	tmpl.Execute(w, result)
+}
+
+// getTGPosts — последние посты канала, новые первыми. Просмотры есть только в истории канала
+// (MTProto или публичная страница t.me/s), поэтому она главный источник; посты, сохранённые ботом, дополняют её
+func getTGPosts(count int) ([]tg.Post, string, error) {
+	stored, _ := tgClient.GetChannelPosts(count)
+
+	parsed, source, err := fetchTGHistory(count, time.Time{})
+	if err != nil {
+		fmt.Printf("❌ %s: %v\n", source, err)
 	}
+	return mergeTGPosts(stored, parsed, source, count)
+}
 
-	stats := []postStat{}
-	totalViews, totalLikes, totalReposts, totalComments := 0, 0, 0, 0
+// getTGPostsInRange — посты за период [from, to]: история листается назад до начала периода
+func getTGPostsInRange(from, to time.Time) ([]tg.Post, string, error) {
+	var stored []tg.Post
+	if tgClient.Store != nil {
+		stored = tgClient.Store.Range(from, to)
+	}
 
+	posts, source, err := fetchTGHistory(0, from)
+	if source == "" {
+		return mergeTGPosts(stored, nil, "", 0)
+	}
+	if err != nil {
+		fmt.Printf("❌ %s: %v\n", source, err)
+		if len(posts) == 0 && len(stored) == 0 {
+			return nil, "", fmt.Errorf("%s недоступен: %v", source, err)
+		}
+	}
+	parsed := []tg.Post{}
 	for _, p := range posts {
-		date := time.Unix(int64(p.Date), 0).Format("02.01.2006 15:04")
-		link := fmt.Sprintf("https://vk.com/wall%d_%d", groupID, p.ID)
-		text := p.Text
-		if len(text) > 150 {
-			text = text[:150] + "..."
+		if !time.Unix(int64(p.Date), 0).After(to) {
+			parsed = append(parsed, p)
 		}
+	}
+	if len(parsed) == 0 && len(stored) == 0 {
+		// Источник ответил, просто постов за период нет
+		return []tg.Post{}, source, nil
+	}
+	return mergeTGPosts(stored, parsed, source, 0)
+}
 
-		stats = append(stats, postStat{
-			Date:     date,
-			Link:     link,
-			Text:     text,
-			Views:    p.Views.Count,
-			Likes:    p.Likes.Count,
-			Reposts:  p.Reposts.Count,
-			Comments: p.Comments.Count,
-		})
+// fetchTGHistory читает историю канала через MTProto, если он настроен, иначе с t.me/s.
+// Пустой source — истории не достать: канал не публичный, а MTProto не настроен
+func fetchTGHistory(limit int, since time.Time) ([]tg.Post, string, error) {
+	if tgMTProto != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		posts, err := tgMTProto.GetPosts(ctx, limit, since)
+		if err == nil {
+			return posts, "MTProto", nil
+		}
+		fmt.Println("❌ MTProto:", err)
+	}
 
-		totalViews += p.Views.Count
-		totalLikes += p.Likes.Count
-		totalReposts += p.Reposts.Count
-		totalComments += p.Comments.Count
+	if !tgChannelIsPublic() {
+		return nil, "", nil
+	}
+	posts, err := tgPublic.GetPosts(limit, since)
+	return posts, "t.me", err
+}
+
+// t.me/s доступна только для публичных каналов с @username
+func tgChannelIsPublic() bool {
+	return strings.HasPrefix(cfg.Telegram.ChannelID, "@")
+}
+
+// mergeTGPosts объединяет посты бота и истории канала. У поста из истории есть просмотры,
+// но текст медиа-поста на t.me/s бывает пустым — тогда берётся текст, сохранённый ботом
+func mergeTGPosts(stored, parsed []tg.Post, source string, limit int) ([]tg.Post, string, error) {
+	byID := map[int]tg.Post{}
+	sources := []string{}
+
+	for _, p := range stored {
+		byID[p.MessageID] = p
+	}
+	if len(stored) > 0 {
+		sources = append(sources, "бот")
+	}
+	for _, p := range parsed {
+		if old, found := byID[p.MessageID]; found && p.Text == "" {
+			p.Text = old.Text
+		}
+		byID[p.MessageID] = p
+	}
+	if len(parsed) > 0 {
+		sources = append(sources, source)
 	}
 
-	result := map[string]interface{}{
-		"Stats": stats,
-		"N":     count,
-		"Totals": map[string]int{
-			"Views":    totalViews,
-			"Likes":    totalLikes,
-			"Reposts":  totalReposts,
-			"Comments": totalComments,
-		},
+	if len(sources) == 0 {
+		return nil, "", fmt.Errorf("нет постов Telegram: канал не публичный, а бот ещё ничего не сохранил")
 	}
 
-	dataCache.Set(cacheKey, result, 30*time.Minute)
+	posts := make([]tg.Post, 0, len(byID))
+	for _, p := range byID {
+		posts = append(posts, p)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].MessageID > posts[j].MessageID })
+	if limit > 0 && len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, strings.Join(sources, " + "), nil
+}
 
-	tmpl := template.Must(template.ParseFiles("templates/posts_analysis.html"))
-	tmpl.Execute(w, result)
+type tgReactionsPage struct {
+	N       int
+	Report  report.ReactionsReport
+	Overall template.HTML
+	Weekly  template.HTML
+	Source  string
 }
 
-func dateRangeHandler(w http.ResponseWriter, r *http.Request) {
-	var report map[string]interface{}
+// Сколько самых частых эмодзи показывать в таблицах и на графике по неделям
+const tgTopReactions = 6
 
+func tgReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	count := 100
 	if r.Method == "POST" {
-		dateFrom := r.FormValue("date_from")
-		dateTo := r.FormValue("date_to")
+		c, _ := strconv.Atoi(r.FormValue("n"))
+		if c >= 10 && c <= 500 {
+			count = c
+		}
+	}
 
-		startDate, err1 := time.Parse("02.01.2006", dateFrom)
-		endDate, err2 := time.Parse("02.01.2006", dateTo)
+	cacheKey := fmt.Sprintf("tg_reactions_%d", count)
 
-		if err1 != nil || err2 != nil {
-			report = map[string]interface{}{"Error": "Неверный формат даты (ДД.ММ.ГГГГ)"}
-		} else {
-			endDate = endDate.Add(23*time.Hour + 59*time.Minute)
+	if cached, found := dataCache.Get(cacheKey); found {
+		tmpl := template.Must(template.ParseFiles("templates/tg_reactions.html"))
+		tmpl.Execute(w, cached)
+		return
+	}
 
-			allPosts := []vk.Post{}
-			offset := 0
-			for {
-				posts, err := vkClient.GetWallPostsWithOffset(groupID, 100, offset)
-				if err != nil || len(posts) == 0 {
-					break
-				}
+	posts, source, err := getTGPosts(count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-				shouldBreak := false
-				for _, post := range posts {
-					postTime := time.Unix(int64(post.Date), 0)
-					if postTime.After(endDate) {
-						continue
-					}
-					if postTime.Before(startDate) {
-						shouldBreak = true
-						break
-					}
-					allPosts = append(allPosts, post)
-				}
+	reactions := report.BuildReactionsReport(post.FromTGPosts(cfg.Telegram.ChannelID, posts), tgTopReactions)
 
-				if shouldBreak {
-					break
-				}
-				offset += 100
-			}
+	bars := []chart.Bar{}
+	for _, s := range reactions.Overall.Reactions {
+		bars = append(bars, chart.Bar{Label: s.Label(), Value: s.Count})
+	}
 
-			type postStat struct {
-				Date, Link, Text                string
-				Views, Likes, Reposts, Comments int
-			}
+	weeks := []string{}
+	for _, week := range reactions.Weeks {
+		weeks = append(weeks, week.Week.Format("02.01"))
+	}
+	series := []chart.Series{}
+	for i, s := range reactions.Emojis {
+		series = append(series, chart.Series{
+			Name:   s.Label(),
+			Color:  chart.Palette[i%len(chart.Palette)],
+			Values: reactions.WeekCounts(s.Emoji),
+		})
+	}
+
+	result := tgReactionsPage{
+		N:       count,
+		Report:  reactions,
+		Overall: chart.BarChart(bars, chart.ColorPink),
+		Weekly:  chart.StackedBarChart(weeks, series),
+		Source:  source,
+	}
 
-			stats := []postStat{}
-			totalViews, totalLikes, totalReposts, totalComments := 0, 0, 0, 0
+	dataCache.Set(cacheKey, result, 30*time.Minute)
 
-			for _, p := range allPosts {
-				text := p.Text
-				if len(text) > 150 {
-					text = text[:150] + "..."
-				}
+	tmpl := template.Must(template.ParseFiles("templates/tg_reactions.html"))
+	tmpl.Execute(w, result)
+}
 
-				stats = append(stats, postStat{
-					Date:     time.Unix(int64(p.Date), 0).Format("02.01.2006 15:04"),
-					Link:     fmt.Sprintf("https://vk.com/wall%d_%d", groupID, p.ID),
-					Text:     text,
-					Views:    p.Views.Count,
-					Likes:    p.Likes.Count,
-					Reposts:  p.Reposts.Count,
-					Comments: p.Comments.Count,
-				})
-
-				totalViews += p.Views.Count
-				totalLikes += p.Likes.Count
-				totalReposts += p.Reposts.Count
-				totalComments += p.Comments.Count
-			}
+type tgDateRangePage struct {
+	Error  string
+	Report *report.TGRangeReport
+	Chart  template.HTML
+	Source string
+}
 
-			avg := func(total, count int) int {
-				if count == 0 {
-					return 0
+func tgDateRangeHandler(w http.ResponseWriter, r *http.Request) {
+	page := tgDateRangePage{}
+
+	if r.Method == "POST" {
+		startDate, err1 := time.ParseInLocation("02.01.2006", r.FormValue("date_from"), time.Local)
+		endDate, err2 := time.ParseInLocation("02.01.2006", r.FormValue("date_to"), time.Local)
+
+		if err1 != nil || err2 != nil {
+			page.Error = "Неверный формат даты (ДД.ММ.ГГГГ)"
+		} else {
+			endDate = endDate.Add(23*time.Hour + 59*time.Minute)
+			posts, source, err := getTGPostsInRange(startDate, endDate)
+			if err != nil {
+				page.Error = err.Error()
+			} else {
+				rangeReport := report.BuildTGRangeReport(cfg.Telegram.ChannelID, startDate, endDate, posts)
+
+				points := []chart.Point{}
+				for _, day := range rangeReport.Daily {
+					points = append(points, chart.Point{Label: day.Date.Format("02.01"), Value: day.Totals.Views})
 				}
-				return total / count
-			}
 
-			report = map[string]interface{}{
-				"Period": fmt.Sprintf("%s – %s", dateFrom, dateTo),
-				"Count":  len(stats),
-				"Stats":  stats,
-				"Totals": map[string]int{"Views": totalViews, "Likes": totalLikes, "Reposts": totalReposts, "Comments": totalComments},
-				"Avg":    map[string]int{"Views": avg(totalViews, len(stats)), "Likes": avg(totalLikes, len(stats)), "Reposts": avg(totalReposts, len(stats)), "Comments": avg(totalComments, len(stats))},
+				page.Report = &rangeReport
+				page.Chart = chart.LineChart(points, chart.ColorBlue)
+				page.Source = source
 			}
 		}
 	}
 
-	tmpl := template.Must(template.ParseFiles("templates/date_range.html"))
-	tmpl.Execute(w, map[string]interface{}{"Report": report})
+	tmpl := template.Must(template.ParseFiles("templates/tg_date_range.html"))
+	tmpl.Execute(w, page)
 }
 
-func clearCacheHandler(w http.ResponseWriter, r *http.Request) {
-	dataCache.Clear()
-	fmt.Println("🗑️ Кэш очищен")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+type tgEmployeeActivityPage struct {
+	N      int
+	Report report.TGEmployeeActivityReport
+	Chart  template.HTML
+	// Discussion — настроена ли группа обсуждения, без неё комментарии не собираются
+	Discussion bool
 }
 
-// ========== TELEGRAM ФУНКЦИОНАЛ ==========
-
-func tgIndexHandler(w http.ResponseWriter, r *http.Request) {
-	tmpl := template.Must(template.ParseFiles("templates/tg_index.html"))
-	tmpl.Execute(w, map[string]string{
-		"ChannelName": "Kait.20 Telegram",
-		"ChannelURL":  "https://t.me/kait_20_official",
-	})
-}
+func tgEmployeeActivityHandler(w http.ResponseWriter, r *http.Request) {
+	count := 30
+	if r.Method == "POST" {
+		c, _ := strconv.Atoi(r.FormValue("n"))
+		if c > 0 && c <= 100 {
+			count = c
+		}
+	}
 
-func tgPostsAnalysisHandler(w http.ResponseWriter, r *http.Request) {
-	// Демо-данные для Telegram
-	demoStats := []map[string]interface{}{
-		{"Date": "23.02.2026 14:00", "Text": "Привет студенты! 🎓", "Views": 1250, "Reactions": 45, "Forwards": 12},
-		{"Date": "22.02.2026 10:30", "Text": "День защитника Отечества! 🇷🇺", "Views": 2100, "Reactions": 89, "Forwards": 34},
-		{"Date": "21.02.2026 16:20", "Text": "Расписание на следующую неделю", "Views": 980, "Reactions": 23, "Forwards": 8},
+	posts, _, err := getTGPosts(count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	totalViews := 0
-	totalReactions := 0
-	totalForwards := 0
+	postIDs := []int{}
+	for _, p := range posts {
+		postIDs = append(postIDs, p.MessageID)
+	}
+	var comments map[int][]tg.Comment
+	if tgClient.Store != nil {
+		comments = tgClient.Store.Comments(postIDs)
+	}
+	activity := report.BuildTGEmployeeActivity(cfg.Telegram.ChannelID, posts, tgEmployees, comments)
 
-	for _, stat := range demoStats {
-		totalViews += stat["Views"].(int)
-		totalReactions += stat["Reactions"].(int)
-		totalForwards += stat["Forwards"].(int)
+	names := []string{}
+	commentsSeries := chart.Series{Name: "Комментарии", Color: chart.ColorBlue}
+	for _, e := range activity.Employees {
+		names = append(names, e.Employee.Name)
+		commentsSeries.Values = append(commentsSeries.Values, e.Stats.Comments)
 	}
 
-	tmpl := template.Must(template.ParseFiles("templates/tg_posts_analysis.html"))
-	tmpl.Execute(w, map[string]interface{}{
-		"Stats": demoStats,
-		"N":     len(demoStats),
-		"Totals": map[string]int{
-			"Views":     totalViews,
-			"Reactions": totalReactions,
-			"Forwards":  totalForwards,
-		},
+	tmpl := template.Must(template.ParseFiles("templates/tg_employee_activity.html"))
+	tmpl.Execute(w, tgEmployeeActivityPage{
+		N:          count,
+		Report:     activity,
+		Chart:      chart.StackedBarChart(names, []chart.Series{commentsSeries}),
+		Discussion: cfg.Telegram.DiscussionID != "",
 	})
 }
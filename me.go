@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"smm-helper/auth"
+	"smm-helper/report"
+
+	"github.com/gorilla/mux"
+)
+
+// ========== ЛИЧНАЯ СТРАНИЦА СОТРУДНИКА ==========
+
+// Сколько последних постов проверяем на личной странице
+const personalPostsCount = 30
+
+type personalPage struct {
+	Report report.PersonalReport
+	Link   string
+	// Напоминания: включён ли бот, отписался ли сотрудник и куда отправлять форму
+	RemindersEnabled bool
+	RemindersOff     bool
+	RemindersAction  string
+}
+
+// meHandler — личная страница по сессии. Админ и менеджер могут открыть любого через ?id=
+func meHandler(w http.ResponseWriter, r *http.Request) {
+	session, _ := auth.FromContext(r.Context())
+
+	employeeID := session.VKID
+	if session.CanViewReports() {
+		if id, err := strconv.Atoi(r.URL.Query().Get("id")); err == nil {
+			employeeID = id
+		}
+	}
+	if employeeID == 0 && session.CanViewReports() {
+		http.Redirect(w, r, "/employee_activity", http.StatusSeeOther)
+		return
+	}
+	if employeeID == 0 {
+		http.Error(w, "К пользователю не привязан VK ID — обратитесь к администратору", http.StatusNotFound)
+		return
+	}
+
+	// Переключать напоминания по сессии можно только себе
+	action := ""
+	if employeeID == session.VKID {
+		action = "/me/reminders"
+	}
+	renderPersonalPage(w, employeeID, action)
+}
+
+// signedMeHandler — та же страница по подписанной ссылке, без входа
+func signedMeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	employeeID, _ := strconv.Atoi(vars["id"])
+
+	if !linkSigner.Verify(employeeID, vars["sig"]) {
+		http.Error(w, "Ссылка недействительна", http.StatusForbidden)
+		return
+	}
+
+	renderPersonalPage(w, employeeID, personalLink(employeeID)+"/reminders")
+}
+
+func renderPersonalPage(w http.ResponseWriter, employeeID int, remindersAction string) {
+	activity, err := getEmployeeActivity(personalPostsCount)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	personal, found := report.BuildPersonalReport(activity, employeeID)
+	if !found {
+		http.Error(w, "Сотрудник не найден", http.StatusNotFound)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles("templates/me.html"))
+	tmpl.Execute(w, personalPage{
+		Report:           personal,
+		Link:             personalLink(employeeID),
+		RemindersEnabled: cfg.Reminders.Enabled,
+		RemindersOff:     reminderBot.State().OptedOut(employeeID),
+		RemindersAction:  remindersAction,
+	})
+}
+
+func personalLink(employeeID int) string {
+	return fmt.Sprintf("/me/%d/%s", employeeID, linkSigner.Sign(employeeID))
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Attachment — файл во вложении письма или документ в Telegram
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// smtpTimeout — сколько ждать SMTP сервер на всю отправку письма, если Mailer.Timeout не задан
+const smtpTimeout = 30 * time.Second
+
+// Mailer отправляет письма через SMTP. Авторизация только если задан Username —
+// так можно слать через локальный тестовый SMTP сервер без логина
+type Mailer struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+func (m *Mailer) Send(to []string, subject, body string, attachments ...Attachment) error {
+	if m.Addr == "" {
+		return fmt.Errorf("smtp: не задан адрес сервера")
+	}
+
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	msg := buildMessage(m.From, to, subject, body, attachments)
+
+	// smtp.SendMail ждёт сервер без ограничений, поэтому соединение открываем сами с дедлайном
+	timeout := m.Timeout
+	if timeout == 0 {
+		timeout = smtpTimeout
+	}
+	conn, err := net.DialTimeout("tcp", m.Addr, timeout)
+	if err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: %v", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("smtp: %v", err)
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, host)); err != nil {
+			return fmt.Errorf("smtp: %v", err)
+		}
+	}
+	if err := c.Mail(m.From); err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return fmt.Errorf("smtp: %v", err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	return c.Quit()
+}
+
+// buildMessage собирает письмо: текст в UTF-8 и вложения в base64
+func buildMessage(from string, to []string, subject, body string, attachments []Attachment) []byte {
+	var buf bytes.Buffer
+	boundary := "smm-" + fmt.Sprint(time.Now().UnixNano())
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64(&buf, []byte(body))
+
+	for _, a := range attachments {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s\r\n", a.ContentType)
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&buf, "Content-Disposition: attachment; filename=%q\r\n\r\n", a.Filename)
+		writeBase64(&buf, a.Data)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes()
+}
+
+// writeBase64 пишет base64 строками по 76 символов, как требует MIME
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
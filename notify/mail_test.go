@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpMessage — письмо, принятое тестовым SMTP сервером
+type smtpMessage struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// fakeSMTP — минимальный SMTP сервер на случайном порту: принимает одно письмо без авторизации
+func fakeSMTP(t *testing.T) (string, <-chan smtpMessage) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		msg := smtpMessage{}
+
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				msg.From = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				msg.To = append(msg.To, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				if msg.Data, err = tp.ReadDotBytes(); err != nil {
+					return
+				}
+				tp.PrintfLine("250 OK")
+				received <- msg
+			case cmd == "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestMailerSend(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	m := &Mailer{Addr: addr, From: "smm@example.com"}
+	csv := []byte("\ufeffДата;Просмотры\n01.03.2024;120\n")
+
+	err := m.Send([]string{"boss@example.com", "team@example.com"}, "Дайджест за неделю", "Итоги недели: 5 постов",
+		Attachment{Filename: "digest.csv", ContentType: "text/csv; charset=utf-8", Data: csv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := <-received
+
+	if got.From != "smm@example.com" || strings.Join(got.To, ",") != "boss@example.com,team@example.com" {
+		t.Errorf("конверт: from %q, to %v", got.From, got.To)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(got.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Дайджест за неделю" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	parts := []struct {
+		contentType string
+		filename    string
+		data        []byte
+	}{}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc := p.Header.Get("Content-Transfer-Encoding"); enc != "base64" {
+			t.Errorf("Content-Transfer-Encoding = %q", enc)
+		}
+		raw, _ := io.ReadAll(p)
+		for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\r\n") {
+			if len(line) > 76 {
+				t.Errorf("строка base64 длиннее 76 символов: %d", len(line))
+			}
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(raw), "\r\n", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, struct {
+			contentType string
+			filename    string
+			data        []byte
+		}{p.Header.Get("Content-Type"), p.FileName(), data})
+	}
+
+	if len(parts) != 2 {
+		t.Fatalf("частей в письме %d, ожидалось 2", len(parts))
+	}
+	if parts[0].contentType != "text/plain; charset=utf-8" || string(parts[0].data) != "Итоги недели: 5 постов" {
+		t.Errorf("текст письма: %q %q", parts[0].contentType, parts[0].data)
+	}
+	if parts[1].contentType != "text/csv; charset=utf-8" || parts[1].filename != "digest.csv" || !bytes.Equal(parts[1].data, csv) {
+		t.Errorf("вложение: %q %q %q", parts[1].contentType, parts[1].filename, parts[1].data)
+	}
+}
+
+func TestMailerSendWithoutAddr(t *testing.T) {
+	if err := (&Mailer{From: "smm@example.com"}).Send([]string{"a@example.com"}, "s", "b"); err == nil {
+		t.Error("ожидалась ошибка без адреса сервера")
+	}
+}
+
+// Сервер принял соединение и молчит — отправка обрывается по таймауту, а не висит
+func TestMailerSendTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		time.Sleep(time.Second)
+	}()
+
+	m := &Mailer{Addr: ln.Addr().String(), From: "smm@example.com", Timeout: 100 * time.Millisecond}
+	start := time.Now()
+	if err := m.Send([]string{"a@example.com"}, "s", "b"); err == nil {
+		t.Error("ожидалась ошибка по таймауту")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("отправка ждала %v, таймаут 100ms", elapsed)
+	}
+}
+
+// Длинное вложение режется на строки, чтобы не упереться в лимит длины строки SMTP
+func TestWriteBase64(t *testing.T) {
+	var buf bytes.Buffer
+	data := bytes.Repeat([]byte("x"), 200)
+	writeBase64(&buf, data)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(lines) != 4 {
+		t.Errorf("строк %d, ожидалось 4", len(lines))
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("после декодирования %q (%v)", decoded, err)
+	}
+}
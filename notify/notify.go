@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"smm-helper/tg"
+)
+
+const (
+	VKAPIURL     = "https://api.vk.com/method/"
+	vkAPIVersion = "5.131"
+)
+
+// Sender отправляет личное сообщение. to — chat_id в Telegram или user_id в VK
+type Sender interface {
+	Name() string
+	Send(ctx context.Context, to int64, text string) error
+}
+
+// TelegramSender шлёт сообщения через Bot API. Запросы выполняет tg.Client — тот же,
+// что публикует посты в канал. baseURL можно подменить на тестовый сервер
+type TelegramSender struct {
+	client *tg.Client
+}
+
+func NewTelegramSender(token, baseURL string) *TelegramSender {
+	client := tg.NewClient(token, "")
+	if baseURL != "" {
+		client.BaseURL = baseURL
+	}
+	return &TelegramSender{client: client}
+}
+
+func (s *TelegramSender) Name() string {
+	return "telegram"
+}
+
+func (s *TelegramSender) Send(ctx context.Context, to int64, text string) error {
+	_, err := s.client.SendText(ctx, strconv.FormatInt(to, 10), text)
+	return err
+}
+
+// SendDocument отправляет файл с подписью (sendDocument)
+func (s *TelegramSender) SendDocument(ctx context.Context, to int64, doc Attachment, caption string) error {
+	_, err := s.client.SendDocument(ctx, strconv.FormatInt(to, 10), tg.InputFile{Name: doc.Filename, Data: doc.Data}, caption)
+	return err
+}
+
+// VKSender шлёт сообщения от имени сообщества через messages.send.
+// Нужен токен сообщества с правом messages, а сотрудник должен разрешить сообщения от группы
+type VKSender struct {
+	Token      string
+	BaseURL    string
+	httpClient *http.Client
+}
+
+func NewVKSender(token, baseURL string) *VKSender {
+	if baseURL == "" {
+		baseURL = VKAPIURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &VKSender{
+		Token:      token,
+		BaseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (s *VKSender) Name() string {
+	return "vk"
+}
+
+func (s *VKSender) Send(ctx context.Context, to int64, text string) error {
+	form := url.Values{}
+	form.Set("access_token", s.Token)
+	form.Set("v", vkAPIVersion)
+	form.Set("user_id", strconv.FormatInt(to, 10))
+	form.Set("random_id", strconv.Itoa(rand.Int()))
+	form.Set("message", text)
+	form.Set("dont_parse_links", "1")
+
+	body, err := postForm(ctx, s.httpClient, s.BaseURL+"messages.send", form)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Error *struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"error_msg"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("vk: неверный ответ: %v", err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("vk: %d %s", result.Error.Code, result.Error.Message)
+	}
+	return nil
+}
+
+// DryRunSender ничего не отправляет, только печатает сообщение в лог
+type DryRunSender struct {
+	Channel string
+}
+
+func (s DryRunSender) Name() string {
+	return s.Channel + " (dry-run)"
+}
+
+func (s DryRunSender) Send(ctx context.Context, to int64, text string) error {
+	fmt.Printf("📭 [dry-run] %s → %d:\n%s\n", s.Channel, to, text)
+	return nil
+}
+
+func postForm(ctx context.Context, client *http.Client, endpoint string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
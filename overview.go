@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"smm-helper/chart"
+	"smm-helper/post"
+	"smm-helper/report"
+)
+
+// ========== СВОДКА VK + TELEGRAM ==========
+
+// По умолчанию сводка за последнюю неделю
+const overviewDays = 7
+
+type overviewPage struct {
+	DateFrom string
+	DateTo   string
+	Error    string
+	// TGError — Telegram недоступен, сводка только по VK
+	TGError string
+	Report  *report.Overview
+	// Графики по дням: столбцы VK и Telegram рядом
+	ViewsChart      template.HTML
+	EngagementChart template.HTML
+	TGSource        string
+}
+
+// Пороги сопоставления для подписи под таблицей
+func (overviewPage) MinSimilarity() int {
+	return int(report.MinSimilarity * 100)
+}
+
+func (overviewPage) WindowHours() int {
+	return int(report.MatchWindow.Hours())
+}
+
+func overviewHandler(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	from := report.TruncateDay(to).AddDate(0, 0, -(overviewDays - 1))
+	page := overviewPage{DateFrom: from.Format("02.01.2006"), DateTo: to.Format("02.01.2006")}
+
+	if r.Method == "POST" {
+		page.DateFrom = r.FormValue("date_from")
+		page.DateTo = r.FormValue("date_to")
+		startDate, err1 := time.ParseInLocation("02.01.2006", page.DateFrom, time.Local)
+		endDate, err2 := time.ParseInLocation("02.01.2006", page.DateTo, time.Local)
+		if err1 != nil || err2 != nil {
+			page.Error = "Неверный формат даты (ДД.ММ.ГГГГ)"
+			renderOverview(w, page)
+			return
+		}
+		from, to = startDate, endDate.Add(23*time.Hour+59*time.Minute)
+	}
+
+	cacheKey := fmt.Sprintf("overview_%d_%d", from.Unix(), to.Unix()/600)
+	if cached, found := dataCache.Get(cacheKey); found {
+		renderOverview(w, cached.(overviewPage))
+		return
+	}
+
+	vkPosts := post.FromVKPosts(groupID, getPostsInRange(from, to))
+
+	tgPosts := []post.Post{}
+	posts, source, err := getTGPostsInRange(from, to)
+	if err != nil {
+		page.TGError = err.Error()
+	} else {
+		tgPosts = post.FromTGPosts(cfg.Telegram.ChannelID, posts)
+		page.TGSource = source
+	}
+
+	overview := report.BuildOverview(from, to, vkPosts, tgPosts)
+	page.Report = &overview
+
+	// Дни обеих площадок совпадают, период один
+	days := []string{}
+	vkViews := chart.Series{Name: "VK", Color: chart.ColorBlue}
+	tgViews := chart.Series{Name: "Telegram", Color: chart.ColorGreen}
+	vkEngagement, tgEngagement := vkViews, tgViews
+	for i, day := range overview.VK.Daily {
+		tgDay := overview.Telegram.Daily[i]
+		days = append(days, day.Date.Format("02.01"))
+		vkViews.Values = append(vkViews.Values, day.Totals.Views)
+		tgViews.Values = append(tgViews.Values, tgDay.Totals.Views)
+		vkEngagement.Values = append(vkEngagement.Values, day.Totals.Engagement())
+		tgEngagement.Values = append(tgEngagement.Values, tgDay.Totals.Engagement())
+	}
+	page.ViewsChart = chart.GroupedBarChart(days, []chart.Series{vkViews, tgViews})
+	page.EngagementChart = chart.GroupedBarChart(days, []chart.Series{vkEngagement, tgEngagement})
+
+	dataCache.Set(cacheKey, page, 10*time.Minute)
+	renderOverview(w, page)
+}
+
+func renderOverview(w http.ResponseWriter, page overviewPage) {
+	tmpl := template.Must(template.ParseFiles("templates/overview.html"))
+	tmpl.Execute(w, page)
+}
@@ -0,0 +1,337 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"smm-helper/storage"
+)
+
+// Status — этап пункта плана
+type Status string
+
+const (
+	Draft     Status = "draft"
+	Approved  Status = "approved"
+	Published Status = "published"
+)
+
+var Statuses = []Status{Draft, Approved, Published}
+
+func (s Status) Title() string {
+	switch s {
+	case Draft:
+		return "черновик"
+	case Approved:
+		return "утверждён"
+	case Published:
+		return "опубликован"
+	}
+	return string(s)
+}
+
+// Откуда взялась версия пункта
+const (
+	SourceAI   = "ai"
+	SourceEdit = "edit"
+)
+
+// Content — то, что меняется от версии к версии
+type Content struct {
+	Time     string `json:"time"`
+	Theme    string `json:"theme"`
+	Text     string `json:"text"`
+	Hashtags string `json:"hashtags"`
+	MediaTip string `json:"media_tip"`
+}
+
+// Version — снимок пункта: черновик ИИ или правка человека
+type Version struct {
+	Content
+	At     time.Time `json:"at"`
+	Source string    `json:"source"`
+	Author string    `json:"author,omitempty"`
+}
+
+func (v Version) FromAI() bool {
+	return v.Source == SourceAI
+}
+
+// Item — один пост плана. Текущее содержимое — последняя версия
+type Item struct {
+	Content
+	Date     time.Time `json:"date"`
+	Status   Status    `json:"status"`
+	Versions []Version `json:"versions"` // от старых к новым
+	// PostID — пост VK, созданный из пункта (обычно отложенный)
+	PostID int `json:"post_id,omitempty"`
+}
+
+// DayTitle — «Понедельник, 02.01»
+func (it Item) DayTitle() string {
+	name := weekdays[(int(it.Date.Weekday())+6)%7]
+	return strings.ToUpper(name[:2]) + name[2:] + ", " + it.Date.Format("02.01")
+}
+
+// ИИ пишет время по-разному: «10:00», «9.30», «12:00–13:00» — берём первое
+var clockRe = regexp.MustCompile(`(\d{1,2})[:.](\d{2})`)
+
+// PublishTime — дата пункта и время из плана в часовом поясе loc
+func (it Item) PublishTime(loc *time.Location) (time.Time, error) {
+	m := clockRe.FindStringSubmatch(it.Time)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("не удалось разобрать время %q", it.Time)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	if hour > 23 || minute > 59 {
+		return time.Time{}, fmt.Errorf("не удалось разобрать время %q", it.Time)
+	}
+	return time.Date(it.Date.Year(), it.Date.Month(), it.Date.Day(), hour, minute, 0, 0, loc), nil
+}
+
+// Message — текст поста для VK: текст и хештеги отдельным абзацем
+func (it Item) Message() string {
+	text := strings.TrimSpace(it.Text)
+	if tags := strings.TrimSpace(it.Hashtags); tags != "" {
+		text += "\n\n" + tags
+	}
+	return strings.TrimSpace(text)
+}
+
+// AIDraft — последний вариант, который предложил ИИ
+func (it Item) AIDraft() (Version, bool) {
+	for i := len(it.Versions) - 1; i >= 0; i-- {
+		if it.Versions[i].FromAI() {
+			return it.Versions[i], true
+		}
+	}
+	return Version{}, false
+}
+
+// Edited — текст отличается от последнего черновика ИИ
+func (it Item) Edited() bool {
+	draft, ok := it.AIDraft()
+	return ok && draft.Content != it.Content
+}
+
+type Plan struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name"`
+	Week    time.Time `json:"week"` // понедельник недели
+	Theme   string    `json:"theme"`
+	Notes   string    `json:"notes"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Items   []Item    `json:"items"`
+}
+
+// Count — сколько пунктов в статусе
+func (p Plan) Count(status Status) int {
+	n := 0
+	for _, it := range p.Items {
+		if it.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func (p Plan) WeekTitle() string {
+	return p.Week.Format("02.01") + "–" + p.Week.AddDate(0, 0, 6).Format("02.01.2006")
+}
+
+// Store — сохранённые контент-планы в JSON файле
+type Store struct {
+	path string
+	data storeData
+	mu   sync.RWMutex
+}
+
+type storeData struct {
+	NextID int     `json:"next_id"`
+	Plans  []*Plan `json:"plans"`
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := storage.ReadJSON(path, &s.data); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	if s.data.NextID == 0 {
+		s.data.NextID = 1
+	}
+	return s, nil
+}
+
+// WeekStart — понедельник недели t в 00:00
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+var weekdays = []string{"понедельник", "вторник", "среда", "четверг", "пятница", "суббота", "воскресенье"}
+
+// DayDate — дата дня из плана ИИ («Понедельник», «среда») в неделе week.
+// Если день не распознан, берётся i-й день недели
+func DayDate(week time.Time, day string, i int) time.Time {
+	day = strings.ToLower(day)
+	for offset, name := range weekdays {
+		if strings.Contains(day, name) {
+			return week.AddDate(0, 0, offset)
+		}
+	}
+	return week.AddDate(0, 0, i%7)
+}
+
+// NewItem — пункт из черновика ИИ
+func NewItem(date time.Time, c Content, at time.Time) Item {
+	return Item{
+		Content:  c,
+		Date:     date,
+		Status:   Draft,
+		Versions: []Version{{Content: c, At: at, Source: SourceAI}},
+	}
+}
+
+// List — планы, новые недели первыми
+func (s *Store) List() []Plan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plans := []Plan{}
+	for _, p := range s.data.Plans {
+		plans = append(plans, clone(p))
+	}
+	sort.SliceStable(plans, func(i, j int) bool {
+		if !plans[i].Week.Equal(plans[j].Week) {
+			return plans[i].Week.After(plans[j].Week)
+		}
+		return plans[i].ID > plans[j].ID
+	})
+	return plans
+}
+
+func (s *Store) Get(id int) (Plan, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p := s.find(id)
+	if p == nil {
+		return Plan{}, false
+	}
+	return clone(p), true
+}
+
+// Create сохраняет новый план и возвращает его ID
+func (s *Store) Create(p Plan) (int, error) {
+	if strings.TrimSpace(p.Name) == "" {
+		return 0, fmt.Errorf("укажите название плана")
+	}
+	if len(p.Items) == 0 {
+		return 0, fmt.Errorf("в плане нет ни одного пункта")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.ID = s.data.NextID
+	s.data.NextID++
+	p.Updated = p.Created
+	s.data.Plans = append(s.data.Plans, &p)
+	return p.ID, s.save()
+}
+
+func (s *Store) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.data.Plans {
+		if p.ID == id {
+			s.data.Plans = append(s.data.Plans[:i], s.data.Plans[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("план %d не найден", id)
+}
+
+// UpdateItem меняет пункт. Новая версия появляется, только если содержимое изменилось
+func (s *Store) UpdateItem(id, index int, c Content, status Status, source, author string, at time.Time) error {
+	if !validStatus(status) {
+		return fmt.Errorf("неизвестный статус %q", status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, err := s.item(id, index)
+	if err != nil {
+		return err
+	}
+
+	if c != item.Content || source == SourceAI {
+		item.Content = c
+		item.Versions = append(item.Versions, Version{Content: c, At: at, Source: source, Author: author})
+	}
+	item.Status = status
+	s.find(id).Updated = at
+	return s.save()
+}
+
+// MarkPublished связывает пункт с постом VK и переводит его в «опубликован»
+func (s *Store) MarkPublished(id, index, postID int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, err := s.item(id, index)
+	if err != nil {
+		return err
+	}
+	item.PostID = postID
+	item.Status = Published
+	s.find(id).Updated = at
+	return s.save()
+}
+
+func (s *Store) find(id int) *Plan {
+	for _, p := range s.data.Plans {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Store) item(id, index int) (*Item, error) {
+	p := s.find(id)
+	if p == nil {
+		return nil, fmt.Errorf("план %d не найден", id)
+	}
+	if index < 0 || index >= len(p.Items) {
+		return nil, fmt.Errorf("в плане нет пункта %d", index+1)
+	}
+	return &p.Items[index], nil
+}
+
+func (s *Store) save() error {
+	return storage.WriteJSON(s.path, s.data)
+}
+
+func validStatus(status Status) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// clone — копия плана, которую можно менять, не трогая хранилище
+func clone(p *Plan) Plan {
+	c := *p
+	c.Items = make([]Item, len(p.Items))
+	for i, it := range p.Items {
+		it.Versions = append([]Version{}, it.Versions...)
+		c.Items[i] = it
+	}
+	return c
+}
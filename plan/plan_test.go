@@ -0,0 +1,202 @@
+package plan
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		// 4 марта 2024 — понедельник
+		{"понедельник утром", date(2024, 3, 4).Add(9 * time.Hour), date(2024, 3, 4)},
+		{"среда", date(2024, 3, 6).Add(15 * time.Hour), date(2024, 3, 4)},
+		{"воскресенье вечером", date(2024, 3, 10).Add(23*time.Hour + 59*time.Minute), date(2024, 3, 4)},
+		{"через границу года", date(2024, 1, 3), date(2024, 1, 1)},
+		{"через границу месяца", date(2024, 3, 2), date(2024, 2, 26)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekStart(tt.t); !got.Equal(tt.want) {
+				t.Errorf("WeekStart(%s) = %s, ожидалось %s", tt.t.Format("02.01.2006 15:04"), got.Format("02.01.2006 15:04"), tt.want.Format("02.01.2006"))
+			}
+		})
+	}
+}
+
+func TestDayDate(t *testing.T) {
+	week := date(2024, 3, 4)
+	tests := []struct {
+		name string
+		day  string
+		i    int
+		want time.Time
+	}{
+		{"с заглавной", "Понедельник", 3, date(2024, 3, 4)},
+		{"строчными", "среда", 0, date(2024, 3, 6)},
+		{"прописными", "ВОСКРЕСЕНЬЕ", 0, date(2024, 3, 10)},
+		{"с датой", "Пятница, 08.03", 0, date(2024, 3, 8)},
+		{"не распознан — по номеру", "День 3", 2, date(2024, 3, 6)},
+		{"сокращение — по номеру", "Вт", 1, date(2024, 3, 5)},
+		{"пусто — по номеру", "", 6, date(2024, 3, 10)},
+		{"номер больше недели", "", 8, date(2024, 3, 5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DayDate(week, tt.day, tt.i); !got.Equal(tt.want) {
+				t.Errorf("DayDate(%q, %d) = %s, ожидалось %s", tt.day, tt.i, got.Format("02.01.2006"), tt.want.Format("02.01.2006"))
+			}
+		})
+	}
+}
+
+func TestPublishTime(t *testing.T) {
+	day := date(2024, 3, 6)
+	tests := []struct {
+		time string
+		want time.Time
+		ok   bool
+	}{
+		{"10:00", day.Add(10 * time.Hour), true},
+		{"9.30", day.Add(9*time.Hour + 30*time.Minute), true},
+		{"12:00–13:00", day.Add(12 * time.Hour), true},
+		{"около 18:45", day.Add(18*time.Hour + 45*time.Minute), true},
+		{"0:05", day.Add(5 * time.Minute), true},
+		{"23:59", day.Add(23*time.Hour + 59*time.Minute), true},
+		{"25:00", time.Time{}, false},
+		{"12:60", time.Time{}, false},
+		{"утром", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.time, func(t *testing.T) {
+			item := Item{Content: Content{Time: tt.time}, Date: day}
+			got, err := item.PublishTime(time.Local)
+			if !tt.ok {
+				if err == nil {
+					t.Errorf("PublishTime(%q) = %s, ожидалась ошибка", tt.time, got)
+				}
+				return
+			}
+			if err != nil || !got.Equal(tt.want) {
+				t.Errorf("PublishTime(%q) = %s, %v, ожидалось %s", tt.time, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func newTestStore(t *testing.T) (*Store, int) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "plans.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	week := date(2024, 3, 4)
+	draft := Content{Time: "10:00", Theme: "Открытые двери", Text: "Ждём всех"}
+	id, err := store.Create(Plan{
+		Name:    "Март",
+		Week:    week,
+		Created: week,
+		Items:   []Item{NewItem(week, draft, week)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, id
+}
+
+func TestUpdateItem(t *testing.T) {
+	store, id := newTestStore(t)
+	at := date(2024, 3, 1)
+	p, _ := store.Get(id)
+	draft := p.Items[0].Content
+	edited := draft
+	edited.Text = "Ждём всех в субботу"
+
+	tests := []struct {
+		name     string
+		content  Content
+		status   Status
+		source   string
+		versions int
+		edited   bool
+	}{
+		{"только статус — без новой версии", draft, Approved, SourceEdit, 1, false},
+		{"правка текста — новая версия", edited, Approved, SourceEdit, 2, true},
+		{"повторное сохранение — без версии", edited, Draft, SourceEdit, 2, true},
+		{"черновик ИИ — всегда новая версия", edited, Draft, SourceAI, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.UpdateItem(id, 0, tt.content, tt.status, tt.source, "anna", at); err != nil {
+				t.Fatal(err)
+			}
+			p, _ := store.Get(id)
+			item := p.Items[0]
+			if len(item.Versions) != tt.versions {
+				t.Errorf("версий %d, ожидалось %d", len(item.Versions), tt.versions)
+			}
+			if item.Status != tt.status || item.Content != tt.content {
+				t.Errorf("пункт %s %+v", item.Status, item.Content)
+			}
+			if item.Edited() != tt.edited {
+				t.Errorf("Edited() = %v, ожидалось %v", item.Edited(), tt.edited)
+			}
+			if !p.Updated.Equal(at) {
+				t.Errorf("Updated = %s", p.Updated)
+			}
+		})
+	}
+
+	p, _ = store.Get(id)
+	if last := p.Items[0].Versions[1]; last.Source != SourceEdit || last.Author != "anna" {
+		t.Errorf("версия правки %+v", last)
+	}
+}
+
+func TestUpdateItemErrors(t *testing.T) {
+	store, id := newTestStore(t)
+	c := Content{Time: "10:00"}
+	tests := []struct {
+		name   string
+		id     int
+		index  int
+		status Status
+	}{
+		{"неизвестный статус", id, 0, "archived"},
+		{"нет плана", id + 1, 0, Draft},
+		{"нет пункта", id, 1, Draft},
+		{"отрицательный номер", id, -1, Draft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.UpdateItem(tt.id, tt.index, c, tt.status, SourceEdit, "", time.Now()); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
+
+func TestMarkPublished(t *testing.T) {
+	store, id := newTestStore(t)
+	if err := store.MarkPublished(id, 0, 555, date(2024, 3, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Пункт переживает перезагрузку хранилища
+	reloaded, err := NewStore(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := reloaded.Get(id)
+	if item := p.Items[0]; item.PostID != 555 || item.Status != Published {
+		t.Errorf("пункт после публикации: PostID %d, статус %s", item.PostID, item.Status)
+	}
+}
@@ -0,0 +1,110 @@
+package plan
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"smm-helper/vk"
+)
+
+// Wall — методы VK, через которые пункты плана уходят на стену. Подходит *vk.Client
+type Wall interface {
+	Publish(ownerID int, d vk.Draft) (int, error)
+	EditPost(ownerID, postID int, d vk.Draft) error
+}
+
+// Publisher создаёт отложенные посты VK из утверждённых пунктов плана.
+// Пункт, у которого пост уже есть, не дублируется: у поста меняются текст и время
+type Publisher struct {
+	Store   *Store
+	Wall    Wall
+	OwnerID int // сообщество, отрицательный ID
+
+	// Отправка идёт по одному запросу на план: иначе двойной клик
+	// или два менеджера разом увидят пункт без поста и создадут два поста
+	mu    sync.Mutex
+	locks map[int]*sync.Mutex
+}
+
+// Result — итог отправки одного пункта. PostID — пост VK, если он создан или изменён
+type Result struct {
+	N       int // номер пункта с 1
+	Day     string
+	Date    time.Time
+	PostID  int
+	Updated bool // пост уже был, у него изменены текст и время
+	Error   string
+}
+
+func NewPublisher(store *Store, wall Wall, ownerID int) *Publisher {
+	return &Publisher{Store: store, Wall: wall, OwnerID: ownerID, locks: make(map[int]*sync.Mutex)}
+}
+
+// Publish отправляет все утверждённые пункты плана. found — есть ли такой план
+func (p *Publisher) Publish(id int, now time.Time) (results []Result, found bool) {
+	lock := p.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// План читаем под блокировкой: предыдущая отправка могла уже привязать посты
+	pl, found := p.Store.Get(id)
+	if !found {
+		return nil, false
+	}
+
+	results = []Result{}
+	for i, item := range pl.Items {
+		if item.Status != Approved {
+			continue
+		}
+		result := Result{N: i + 1, Day: item.DayTitle(), Updated: item.PostID != 0}
+		result.Date, result.PostID, result.Error = p.publishItem(id, i, item, now)
+		results = append(results, result)
+	}
+	return results, true
+}
+
+// publishItem отправляет один пункт в VK и привязывает к нему пост
+func (p *Publisher) publishItem(id, index int, item Item, now time.Time) (time.Time, int, string) {
+	date, err := item.PublishTime(time.Local)
+	if err != nil {
+		return date, 0, err.Error()
+	}
+	if !date.After(now) {
+		return date, 0, "Время публикации уже прошло — поменяйте день или время"
+	}
+	draft := vk.Draft{Message: item.Message(), PublishDate: date}
+	if draft.Message == "" {
+		return date, 0, "Пост пустой: добавьте текст"
+	}
+
+	postID := item.PostID
+	if postID != 0 {
+		err = p.Wall.EditPost(p.OwnerID, postID, draft)
+	} else {
+		postID, err = p.Wall.Publish(p.OwnerID, draft)
+	}
+	if err != nil {
+		return date, 0, err.Error()
+	}
+	fmt.Printf("🤖 Пункт %d плана %d → отложенный пост %d на %s\n", index+1, id, postID, date.Format("02.01.2006 15:04"))
+
+	if err := p.Store.MarkPublished(id, index, postID, now); err != nil {
+		// Пост в VK уже есть: показываем ссылку, чтобы его не создали повторно
+		return date, postID, "Пост создан, но план не сохранён: " + err.Error()
+	}
+	return date, postID, ""
+}
+
+// lock — блокировка отправки плана
+func (p *Publisher) lock(id int) *sync.Mutex {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lock, found := p.locks[id]
+	if !found {
+		lock = &sync.Mutex{}
+		p.locks[id] = lock
+	}
+	return lock
+}
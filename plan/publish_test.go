@@ -0,0 +1,191 @@
+package plan
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"smm-helper/vk"
+)
+
+// fakeWall — wall.post и wall.edit VK, запоминает вызовы
+type fakeWall struct {
+	*httptest.Server
+	mu     sync.Mutex
+	calls  []string // метод и post_id
+	params []map[string]string
+	nextID int
+}
+
+func newFakeWall(t *testing.T) *fakeWall {
+	f := &fakeWall{nextID: 100}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/method/", func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[len("/method/"):]
+		if r.FormValue("access_token") != "token" || r.FormValue("owner_id") != "-123" {
+			t.Errorf("%s: access_token=%s owner_id=%s", method, r.FormValue("access_token"), r.FormValue("owner_id"))
+		}
+		// Как и VK, ждём немного: параллельная отправка успеет дойти сюда дважды, если её не остановить
+		time.Sleep(10 * time.Millisecond)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.params = append(f.params, map[string]string{
+			"message":      r.FormValue("message"),
+			"publish_date": r.FormValue("publish_date"),
+		})
+		switch method {
+		case "wall.post":
+			f.nextID++
+			f.calls = append(f.calls, fmt.Sprintf("wall.post %d", f.nextID))
+			fmt.Fprintf(w, `{"response":{"post_id":%d}}`, f.nextID)
+		case "wall.edit":
+			f.calls = append(f.calls, "wall.edit "+r.FormValue("post_id"))
+			fmt.Fprintf(w, `{"response":{"post_id":%s}}`, r.FormValue("post_id"))
+		default:
+			t.Errorf("неожиданный метод %s", method)
+		}
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeWall) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+// Понедельник 4 марта 2024, полдень
+var publishNow = date(2024, 3, 4).Add(12 * time.Hour)
+
+func newTestPublisher(t *testing.T) (*Publisher, *fakeWall, int) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "plans.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	week := date(2024, 3, 4)
+	item := func(day int, c Content, status Status, postID int) Item {
+		it := NewItem(week.AddDate(0, 0, day), c, week)
+		it.Status, it.PostID = status, postID
+		return it
+	}
+	id, err := store.Create(Plan{Name: "Март", Week: week, Created: week, Items: []Item{
+		item(1, Content{Time: "10:00", Text: "Новый пост", Hashtags: "#колледж"}, Approved, 0),
+		item(2, Content{Time: "18:30", Text: "Уже отправлен"}, Approved, 55),
+		item(0, Content{Time: "9:00", Text: "Утро прошло"}, Approved, 0),
+		item(3, Content{Time: "12:00", Text: "Черновик"}, Draft, 0),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wall := newFakeWall(t)
+	client := vk.NewClient("token")
+	client.APIURL = wall.URL + "/method"
+	return NewPublisher(store, client, -123), wall, id
+}
+
+func TestPublisherPublish(t *testing.T) {
+	p, wall, id := newTestPublisher(t)
+
+	results, found := p.Publish(id, publishNow)
+	if !found {
+		t.Fatal("план не найден")
+	}
+
+	// Черновик не отправляется, пункт с постом правится, а не публикуется заново
+	if calls := wall.Calls(); len(calls) != 2 || calls[0] != "wall.post 101" || calls[1] != "wall.edit 55" {
+		t.Errorf("вызовы VK %v, ожидалось [wall.post 101 wall.edit 55]", calls)
+	}
+	if want := strconv.FormatInt(date(2024, 3, 5).Add(10*time.Hour).Unix(), 10); wall.params[0]["publish_date"] != want {
+		t.Errorf("publish_date = %s, ожидалось %s", wall.params[0]["publish_date"], want)
+	}
+	if msg := wall.params[0]["message"]; msg != "Новый пост\n\n#колледж" {
+		t.Errorf("message = %q", msg)
+	}
+
+	want := []Result{
+		{N: 1, PostID: 101},
+		{N: 2, PostID: 55, Updated: true},
+		{N: 3, Error: "Время публикации уже прошло — поменяйте день или время"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("результатов %d, ожидалось %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.N != w.N || got.PostID != w.PostID || got.Updated != w.Updated || got.Error != w.Error {
+			t.Errorf("результат %d: %+v, ожидалось %+v", i, got, w)
+		}
+	}
+
+	// MarkPublished привязал посты к пунктам, прошедший пункт остался утверждённым
+	pl, _ := p.Store.Get(id)
+	wantItems := []struct {
+		status Status
+		postID int
+	}{{Published, 101}, {Published, 55}, {Approved, 0}, {Draft, 0}}
+	for i, w := range wantItems {
+		if it := pl.Items[i]; it.Status != w.status || it.PostID != w.postID {
+			t.Errorf("пункт %d: %s, пост %d, ожидалось %s, пост %d", i+1, it.Status, it.PostID, w.status, w.postID)
+		}
+	}
+}
+
+// Двойной клик: два запроса разом создают один пост, второй не видит опубликованных пунктов
+func TestPublisherPublishConcurrent(t *testing.T) {
+	p, wall, id := newTestPublisher(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Publish(id, publishNow)
+		}()
+	}
+	wg.Wait()
+
+	posts := 0
+	for _, call := range wall.Calls() {
+		if strings.HasPrefix(call, "wall.post") {
+			posts++
+		}
+	}
+	if posts != 1 {
+		t.Errorf("wall.post вызван %d раз, ожидался 1: %v", posts, wall.Calls())
+	}
+}
+
+func TestPublisherPublishErrors(t *testing.T) {
+	p, _, id := newTestPublisher(t)
+	if _, found := p.Publish(id+1, publishNow); found {
+		t.Error("найден несуществующий план")
+	}
+
+	// Ошибка VK: пункт не привязывается к посту и остаётся утверждённым
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"error_code":214,"error_msg":"Access to adding post denied"}}`))
+	}))
+	defer srv.Close()
+	client := vk.NewClient("token")
+	client.APIURL = srv.URL
+	p.Wall = client
+
+	results, _ := p.Publish(id, publishNow)
+	if len(results) == 0 || results[0].Error != "wall.post: 214 Access to adding post denied" || results[0].PostID != 0 {
+		t.Fatalf("результаты %+v", results)
+	}
+	pl, _ := p.Store.Get(id)
+	if it := pl.Items[0]; it.Status != Approved || it.PostID != 0 {
+		t.Errorf("после ошибки пункт %s, пост %d", it.Status, it.PostID)
+	}
+}
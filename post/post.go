@@ -0,0 +1,87 @@
+package post
+
+import (
+	"math"
+	"time"
+)
+
+// Platform — откуда пост
+type Platform string
+
+const (
+	VK       Platform = "vk"
+	Telegram Platform = "telegram"
+)
+
+// Title — название площадки для заголовков и подписей
+func (p Platform) Title() string {
+	switch p {
+	case VK:
+		return "VK"
+	case Telegram:
+		return "Telegram"
+	}
+	return string(p)
+}
+
+// Metrics — показатели поста в общих для площадок терминах.
+// Reactions — лайки VK или реакции Telegram, Shares — репосты VK или пересылки Telegram
+type Metrics struct {
+	Views     int `json:"views"`
+	Reactions int `json:"reactions"`
+	Shares    int `json:"shares"`
+	Comments  int `json:"comments"`
+}
+
+func (m *Metrics) Add(o Metrics) {
+	m.Views += o.Views
+	m.Reactions += o.Reactions
+	m.Shares += o.Shares
+	m.Comments += o.Comments
+}
+
+// Divide — среднее на n постов, целочисленное, как в остальных отчётах
+func (m Metrics) Divide(n int) Metrics {
+	if n == 0 {
+		return Metrics{}
+	}
+	return Metrics{
+		Views:     m.Views / n,
+		Reactions: m.Reactions / n,
+		Shares:    m.Shares / n,
+		Comments:  m.Comments / n,
+	}
+}
+
+// Engagement — реакции, репосты и комментарии вместе
+func (m Metrics) Engagement() int {
+	return m.Reactions + m.Shares + m.Comments
+}
+
+// ER — вовлечённость на просмотр в процентах, с одним знаком после запятой
+func (m Metrics) ER() float64 {
+	if m.Views == 0 {
+		return 0
+	}
+	return math.Round(float64(m.Engagement())*1000/float64(m.Views)) / 10
+}
+
+// Reaction — число реакций одним эмодзи. У VK одна реакция — лайк
+type Reaction struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// Post — пост любой площадки
+type Post struct {
+	Platform Platform   `json:"platform"`
+	ID       int        `json:"id"`
+	Date     time.Time  `json:"date"`
+	Link     string     `json:"link"`
+	Text     string     `json:"text"`
+	Metrics  Metrics    `json:"metrics"`
+	ByEmoji  []Reaction `json:"by_emoji,omitempty"`
+	// Есть только у Telegram
+	Media         string `json:"media,omitempty"`
+	ForwardedFrom string `json:"forwarded_from,omitempty"`
+}
@@ -0,0 +1,50 @@
+package post
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"smm-helper/tg"
+)
+
+// TGLink — ссылка на пост канала. channel — @username, username или числовой ID вида -100…:
+// у канала без username ссылка t.me/c/<ID без -100>/N, открывается у подписчиков
+func TGLink(channel string, messageID int) string {
+	if _, err := strconv.ParseInt(channel, 10, 64); err == nil {
+		id := strings.TrimPrefix(strings.TrimPrefix(channel, "-100"), "-")
+		return fmt.Sprintf("https://t.me/c/%s/%d", id, messageID)
+	}
+	return fmt.Sprintf("https://t.me/%s/%d", strings.TrimPrefix(channel, "@"), messageID)
+}
+
+// FromTG переводит пост канала из любого источника: бот, t.me/s или MTProto
+func FromTG(channel string, p tg.Post) Post {
+	post := Post{
+		Platform: Telegram,
+		ID:       p.MessageID,
+		Date:     time.Unix(int64(p.Date), 0),
+		Link:     TGLink(channel, p.MessageID),
+		Text:     p.Text,
+		Metrics: Metrics{
+			Views:     p.Views,
+			Reactions: p.Reactions.TotalCount,
+			Shares:    p.Forwards,
+		},
+		Media:         p.Media,
+		ForwardedFrom: p.ForwardedFrom,
+	}
+	for _, r := range p.Reactions.Items {
+		post.ByEmoji = append(post.ByEmoji, Reaction{Emoji: r.Emoji, Count: r.Count})
+	}
+	return post
+}
+
+func FromTGPosts(channel string, posts []tg.Post) []Post {
+	result := make([]Post, 0, len(posts))
+	for _, p := range posts {
+		result = append(result, FromTG(channel, p))
+	}
+	return result
+}
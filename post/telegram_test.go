@@ -0,0 +1,44 @@
+package post
+
+import (
+	"testing"
+
+	"smm-helper/tg"
+)
+
+func TestTGLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		want    string
+	}{
+		{"username с @", "@kait_20_official", "https://t.me/kait_20_official/15"},
+		{"username без @", "kait_20_official", "https://t.me/kait_20_official/15"},
+		{"числовой ID канала", "-1001234567890", "https://t.me/c/1234567890/15"},
+		{"числовой ID без -100", "-123456", "https://t.me/c/123456/15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TGLink(tt.channel, 15); got != tt.want {
+				t.Errorf("TGLink(%q) = %q, ожидалось %q", tt.channel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromTG(t *testing.T) {
+	p := tg.Post{MessageID: 7, Date: 1709550000, Text: "Новость", Views: 120, Forwards: 3}
+	p.Reactions.TotalCount = 5
+	p.Reactions.Items = []tg.ReactionCount{{Emoji: "👍", Count: 4}, {Emoji: "🔥", Count: 1}}
+
+	got := FromTG("-1001234567890", p)
+	if got.Platform != Telegram || got.Link != "https://t.me/c/1234567890/7" {
+		t.Errorf("Platform = %s, Link = %s", got.Platform, got.Link)
+	}
+	if got.Metrics != (Metrics{Views: 120, Reactions: 5, Shares: 3}) {
+		t.Errorf("Metrics = %+v", got.Metrics)
+	}
+	if len(got.ByEmoji) != 2 || got.ByEmoji[0] != (Reaction{Emoji: "👍", Count: 4}) {
+		t.Errorf("ByEmoji = %+v", got.ByEmoji)
+	}
+}
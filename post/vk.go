@@ -0,0 +1,41 @@
+package post
+
+import (
+	"fmt"
+	"time"
+
+	"smm-helper/vk"
+)
+
+func VKLink(ownerID, postID int) string {
+	return fmt.Sprintf("https://vk.com/wall%d_%d", ownerID, postID)
+}
+
+// FromVK переводит пост стены. ownerID — ID сообщества со знаком минус
+func FromVK(ownerID int, p vk.Post) Post {
+	post := Post{
+		Platform: VK,
+		ID:       p.ID,
+		Date:     time.Unix(int64(p.Date), 0),
+		Link:     VKLink(ownerID, p.ID),
+		Text:     p.Text,
+		Metrics: Metrics{
+			Views:     p.Views.Count,
+			Reactions: p.Likes.Count,
+			Shares:    p.Reposts.Count,
+			Comments:  p.Comments.Count,
+		},
+	}
+	if p.Likes.Count > 0 {
+		post.ByEmoji = []Reaction{{Emoji: "❤️", Count: p.Likes.Count}}
+	}
+	return post
+}
+
+func FromVKPosts(ownerID int, posts []vk.Post) []Post {
+	result := make([]Post, 0, len(posts))
+	for _, p := range posts {
+		result = append(result, FromVK(ownerID, p))
+	}
+	return result
+}
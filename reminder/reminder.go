@@ -0,0 +1,297 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"smm-helper/kpi"
+	"smm-helper/notify"
+	"smm-helper/report"
+	"smm-helper/storage"
+)
+
+// Settings — когда и кому напоминать
+type Settings struct {
+	After  time.Duration // через сколько после публикации проверять пост
+	MaxAge time.Duration // старше — уже не напоминаем
+	// Тихие часы по местному времени: с QuietFrom до QuietTo (может переходить через полночь).
+	// Если QuietFrom == QuietTo, тихих часов нет
+	QuietFrom int
+	QuietTo   int
+	DryRun    bool // отметки об отправке только в памяти (Sender при этом — notify.DryRunSender)
+}
+
+// Reminder — одно сообщение сотруднику со всеми пропущенными постами
+type Reminder struct {
+	EmployeeID int          `json:"employee_id"`
+	Name       string       `json:"name"`
+	To         int64        `json:"to"`
+	Posts      []MissedPost `json:"posts"`
+	Text       string       `json:"text"`
+}
+
+type MissedPost struct {
+	report.PostRef
+	NeedLike   bool `json:"need_like"`
+	NeedRepost bool `json:"need_repost"`
+}
+
+// Bot проверяет свежие посты и напоминает сотрудникам, которые их не отработали
+type Bot struct {
+	Sender   notify.Sender
+	Settings Settings
+	// Activity — отчёт активности по последним постам
+	Activity func() (report.EmployeeActivityReport, error)
+	// Rule — цель сотрудника: требование с нулевым процентом не напоминаем
+	Rule func(employeeID int) kpi.Rule
+	// Recipient — куда писать сотруднику; false — адрес неизвестен
+	Recipient func(employeeID int) (int64, bool)
+	// Link — ссылка на личную страницу (там же отписка), может вернуть пустую строку
+	Link func(employeeID int) string
+
+	state *State
+	// В dry-run отметки не попадают в State, чтобы не потерять настоящие напоминания после выключения режима
+	dryRunSent map[string]bool
+	mu         sync.Mutex
+}
+
+func NewBot(sender notify.Sender, settings Settings, state *State) *Bot {
+	return &Bot{Sender: sender, Settings: settings, state: state, dryRunSent: map[string]bool{}}
+}
+
+func (b *Bot) State() *State {
+	return b.state
+}
+
+// Quiet — попадает ли время в тихие часы
+func (s Settings) Quiet(now time.Time) bool {
+	if s.QuietFrom == s.QuietTo {
+		return false
+	}
+	h := now.Hour()
+	if s.QuietFrom < s.QuietTo {
+		return h >= s.QuietFrom && h < s.QuietTo
+	}
+	return h >= s.QuietFrom || h < s.QuietTo
+}
+
+// Plan собирает напоминания, которые ушли бы сейчас, ничего не отправляя
+func (b *Bot) Plan(now time.Time) ([]Reminder, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.plan(now)
+}
+
+func (b *Bot) plan(now time.Time) ([]Reminder, error) {
+	activity, err := b.Activity()
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := []Reminder{}
+	for _, e := range activity.Employees {
+		id := e.Employee.ID
+		if b.state.OptedOut(id) {
+			continue
+		}
+		to, ok := b.Recipient(id)
+		if !ok {
+			continue
+		}
+		rule := b.Rule(id)
+
+		missed := []MissedPost{}
+		for i, a := range e.Activity {
+			post := activity.Posts[i]
+			age := now.Sub(post.Date)
+			if age < b.Settings.After || age > b.Settings.MaxAge {
+				continue
+			}
+
+			m := MissedPost{
+				PostRef:    post,
+				NeedLike:   rule.LikePercent > 0 && !a.Liked,
+				NeedRepost: rule.RepostPercent > 0 && !a.Reposted,
+			}
+			if (m.NeedLike || m.NeedRepost) && !b.sent(post.ID, id) {
+				missed = append(missed, m)
+			}
+		}
+		if len(missed) == 0 {
+			continue
+		}
+
+		// Старые посты первыми — в том порядке, в каком их стоит отработать
+		sort.Slice(missed, func(i, j int) bool { return missed[i].Date.Before(missed[j].Date) })
+
+		r := Reminder{EmployeeID: id, Name: e.Employee.Name, To: to, Posts: missed}
+		r.Text = b.text(r)
+		reminders = append(reminders, r)
+	}
+	return reminders, nil
+}
+
+// Check отправляет напоминания. В тихие часы ничего не делает — посты дождутся следующей проверки
+func (b *Bot) Check(ctx context.Context, now time.Time) ([]Reminder, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Settings.Quiet(now) {
+		return nil, nil
+	}
+
+	reminders, err := b.plan(now)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := []Reminder{}
+	for _, r := range reminders {
+		if err := b.Sender.Send(ctx, r.To, r.Text); err != nil {
+			fmt.Printf("❌ Напоминание %s (%d) через %s: %v\n", r.Name, r.EmployeeID, b.Sender.Name(), err)
+			continue
+		}
+		for _, p := range r.Posts {
+			if b.Settings.DryRun {
+				b.dryRunSent[sentKey(p.ID, r.EmployeeID)] = true
+			} else {
+				b.state.MarkSent(p.ID, r.EmployeeID, now)
+			}
+		}
+		sent = append(sent, r)
+	}
+
+	if len(sent) > 0 && !b.Settings.DryRun {
+		if err := b.state.Save(now); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Run проверяет посты с заданным интервалом, пока не отменён ctx
+func (b *Bot) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := b.Check(ctx, time.Now())
+		if err != nil {
+			fmt.Println("❌ Напоминания:", err)
+		} else if len(sent) > 0 {
+			fmt.Printf("🔔 Отправлено напоминаний: %d (%s)\n", len(sent), b.Sender.Name())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Bot) sent(postID, employeeID int) bool {
+	return b.dryRunSent[sentKey(postID, employeeID)] || b.state.Sent(postID, employeeID)
+}
+
+func (b *Bot) text(r Reminder) string {
+	var sb strings.Builder
+	sb.WriteString("Напоминание: остались неотработанные посты группы\n")
+	for _, p := range r.Posts {
+		todo := []string{}
+		if p.NeedLike {
+			todo = append(todo, "лайк")
+		}
+		if p.NeedRepost {
+			todo = append(todo, "репост")
+		}
+		fmt.Fprintf(&sb, "\n• %s — %s\n%s\n", p.Date.Format("02.01 15:04"), strings.Join(todo, " и "), p.Link)
+	}
+
+	if b.Link != nil {
+		if link := b.Link(r.EmployeeID); link != "" {
+			fmt.Fprintf(&sb, "\nВсе пропуски и отключение напоминаний: %s", link)
+		}
+	}
+	return sb.String()
+}
+
+// State — отметки об отправленных напоминаниях и отписки, хранится в JSON файле
+type State struct {
+	path string
+	data stateData
+	mu   sync.RWMutex
+}
+
+type stateData struct {
+	// Ключ — "<id поста>:<id сотрудника>"
+	Sent   map[string]time.Time `json:"sent"`
+	OptOut map[int]bool         `json:"opt_out"`
+}
+
+// Отметки старше этого срока удаляются при сохранении
+const sentRetention = 30 * 24 * time.Hour
+
+func LoadState(path string) (*State, error) {
+	s := &State{path: path}
+	if err := storage.ReadJSON(path, &s.data); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	if s.data.Sent == nil {
+		s.data.Sent = map[string]time.Time{}
+	}
+	if s.data.OptOut == nil {
+		s.data.OptOut = map[int]bool{}
+	}
+	return s, nil
+}
+
+func sentKey(postID, employeeID int) string {
+	return fmt.Sprintf("%d:%d", postID, employeeID)
+}
+
+func (s *State) Sent(postID, employeeID int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.data.Sent[sentKey(postID, employeeID)]
+	return found
+}
+
+func (s *State) MarkSent(postID, employeeID int, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Sent[sentKey(postID, employeeID)] = at
+}
+
+func (s *State) OptedOut(employeeID int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.OptOut[employeeID]
+}
+
+// SetOptOut включает или отключает напоминания сотруднику и сразу сохраняет состояние
+func (s *State) SetOptOut(employeeID int, optOut bool) error {
+	s.mu.Lock()
+	if optOut {
+		s.data.OptOut[employeeID] = true
+	} else {
+		delete(s.data.OptOut, employeeID)
+	}
+	s.mu.Unlock()
+	return s.Save(time.Now())
+}
+
+func (s *State) Save(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, at := range s.data.Sent {
+		if now.Sub(at) > sentRetention {
+			delete(s.data.Sent, key)
+		}
+	}
+	return storage.WriteJSON(s.path, s.data)
+}
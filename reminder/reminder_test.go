@@ -0,0 +1,234 @@
+package reminder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"smm-helper/kpi"
+	"smm-helper/notify"
+	"smm-helper/report"
+	"smm-helper/vk"
+)
+
+// fakeBotAPI — Bot API, который запоминает sendMessage
+type fakeBotAPI struct {
+	*httptest.Server
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+type sentMessage struct {
+	ChatID string
+	Text   string
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	f := &fakeBotAPI{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("неожиданный запрос %s", r.URL.Path)
+		}
+		f.mu.Lock()
+		f.sent = append(f.sent, sentMessage{ChatID: r.FormValue("chat_id"), Text: r.FormValue("text")})
+		f.mu.Unlock()
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeBotAPI) messages() []sentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]sentMessage{}, f.sent...)
+}
+
+var now = time.Date(2024, 3, 4, 14, 0, 0, 0, time.Local)
+
+// Два поста: свежий (час назад) и слишком старый. Анна не лайкнула свежий, Борис всё сделал
+func testActivity() report.EmployeeActivityReport {
+	return report.EmployeeActivityReport{
+		Posts: []report.PostRef{
+			{ID: 1, Date: now.Add(-time.Hour), Link: "https://vk.com/wall-1_1"},
+			{ID: 2, Date: now.Add(-10 * 24 * time.Hour), Link: "https://vk.com/wall-1_2"},
+		},
+		Employees: []report.EmployeeActivity{
+			{Employee: vk.Employee{ID: 10, Name: "Анна"}, Activity: []report.PostActivity{{PostID: 1}, {PostID: 2}}},
+			{Employee: vk.Employee{ID: 11, Name: "Борис"}, Activity: []report.PostActivity{{PostID: 1, Liked: true, Reposted: true}, {PostID: 2}}},
+		},
+	}
+}
+
+func newTestBot(t *testing.T, sender notify.Sender, settings Settings) (*Bot, string) {
+	path := filepath.Join(t.TempDir(), "reminders.json")
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBot(sender, settings, state)
+	b.Activity = func() (report.EmployeeActivityReport, error) { return testActivity(), nil }
+	b.Rule = func(int) kpi.Rule { return kpi.Rule{LikePercent: 100} }
+	b.Recipient = func(id int) (int64, bool) { return int64(id * 100), true }
+	b.Link = func(int) string { return "" }
+	return b, path
+}
+
+var testSettings = Settings{After: 30 * time.Minute, MaxAge: 3 * 24 * time.Hour, QuietFrom: 22, QuietTo: 8}
+
+func TestCheckSendsAndDeduplicates(t *testing.T) {
+	api := newFakeBotAPI(t)
+	b, path := newTestBot(t, notify.NewTelegramSender("token", api.URL), testSettings)
+
+	sent, err := b.Check(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0].EmployeeID != 10 || len(sent[0].Posts) != 1 || sent[0].Posts[0].ID != 1 {
+		t.Fatalf("отправлено %+v, ожидалось одно напоминание Анне о посте 1", sent)
+	}
+	msgs := api.messages()
+	if len(msgs) != 1 || msgs[0].ChatID != "1000" || !strings.Contains(msgs[0].Text, "https://vk.com/wall-1_1") {
+		t.Fatalf("Bot API получил %+v", msgs)
+	}
+	if !b.State().Sent(1, 10) {
+		t.Error("отметка об отправке не попала в State")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("State не сохранён: %v", err)
+	}
+
+	// Повторная проверка не шлёт то же напоминание — ни этим ботом, ни после перезапуска
+	if sent, _ := b.Check(context.Background(), now.Add(time.Minute)); len(sent) != 0 {
+		t.Errorf("повторно отправлено %+v", sent)
+	}
+	restarted, _ := newTestBot(t, notify.NewTelegramSender("token", api.URL), testSettings)
+	restarted.state, _ = LoadState(path)
+	if sent, _ := restarted.Check(context.Background(), now.Add(time.Minute)); len(sent) != 0 {
+		t.Errorf("после перезапуска повторно отправлено %+v", sent)
+	}
+	if n := len(api.messages()); n != 1 {
+		t.Errorf("Bot API получил %d сообщений, ожидалось 1", n)
+	}
+}
+
+func TestCheckQuietHours(t *testing.T) {
+	tests := []struct {
+		name string
+		hour int
+		sent bool
+	}{
+		{"вечер до тихих часов", 21, true},
+		{"начало тихих часов", 22, false},
+		{"после полуночи", 3, false},
+		{"конец тихих часов", 8, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeBotAPI(t)
+			b, _ := newTestBot(t, notify.NewTelegramSender("token", api.URL), testSettings)
+			at := time.Date(2024, 3, 4, tt.hour, 0, 0, 0, time.Local)
+			b.Activity = func() (report.EmployeeActivityReport, error) {
+				a := testActivity()
+				a.Posts[0].Date = at.Add(-time.Hour)
+				return a, nil
+			}
+
+			sent, err := b.Check(context.Background(), at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(api.messages()) > 0; got != tt.sent || (len(sent) > 0) != tt.sent {
+				t.Errorf("в %d:00 отправлено %v, ожидалось %v", tt.hour, got, tt.sent)
+			}
+		})
+	}
+}
+
+func TestCheckOptOut(t *testing.T) {
+	api := newFakeBotAPI(t)
+	b, _ := newTestBot(t, notify.NewTelegramSender("token", api.URL), testSettings)
+	if err := b.State().SetOptOut(10, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent, _ := b.Check(context.Background(), now); len(sent) != 0 {
+		t.Errorf("отписавшемуся отправлено %+v", sent)
+	}
+	if n := len(api.messages()); n != 0 {
+		t.Errorf("Bot API получил %d сообщений", n)
+	}
+
+	// После возврата подписки напоминание уходит
+	b.State().SetOptOut(10, false)
+	if sent, _ := b.Check(context.Background(), now); len(sent) != 1 {
+		t.Errorf("после подписки отправлено %d напоминаний, ожидалось 1", len(sent))
+	}
+}
+
+func TestCheckDryRunDoesNotPersist(t *testing.T) {
+	api := newFakeBotAPI(t)
+	settings := testSettings
+	settings.DryRun = true
+	b, path := newTestBot(t, notify.NewTelegramSender("token", api.URL), settings)
+
+	if sent, _ := b.Check(context.Background(), now); len(sent) != 1 {
+		t.Fatalf("отправлено %d напоминаний, ожидалось 1", len(sent))
+	}
+	if b.State().Sent(1, 10) {
+		t.Error("в dry-run отметка попала в State")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("в dry-run State записан на диск: %v", err)
+	}
+
+	// В памяти отметка есть — в одном запуске dry-run не повторяется
+	if sent, _ := b.Check(context.Background(), now); len(sent) != 0 {
+		t.Errorf("dry-run повторил напоминание %+v", sent)
+	}
+}
+
+func TestCheckVKSender(t *testing.T) {
+	var got struct {
+		userID, message string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages.send" || r.FormValue("access_token") != "token" {
+			t.Errorf("неожиданный запрос %s", r.URL.Path)
+		}
+		got.userID, got.message = r.FormValue("user_id"), r.FormValue("message")
+		w.Write([]byte(`{"response":1}`))
+	}))
+	defer srv.Close()
+
+	b, _ := newTestBot(t, notify.NewVKSender("token", srv.URL), testSettings)
+	sent, err := b.Check(context.Background(), now)
+	if err != nil || len(sent) != 1 {
+		t.Fatalf("отправлено %d, ошибка %v", len(sent), err)
+	}
+	if got.userID != "1000" || !strings.Contains(got.message, "лайк") {
+		t.Errorf("VK получил user_id=%s message=%q", got.userID, got.message)
+	}
+}
+
+func TestCheckSendErrorNotMarked(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"error_code":901,"error_msg":"Can't send messages"}}`))
+	}))
+	defer srv.Close()
+
+	b, _ := newTestBot(t, notify.NewVKSender("token", srv.URL), testSettings)
+	sent, err := b.Check(context.Background(), now)
+	if err != nil || len(sent) != 0 {
+		t.Fatalf("отправлено %d, ошибка %v", len(sent), err)
+	}
+	if b.State().Sent(1, 10) {
+		t.Error("неотправленное напоминание отмечено как отправленное")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"smm-helper/auth"
+	"smm-helper/kpi"
+	"smm-helper/notify"
+	"smm-helper/reminder"
+	"smm-helper/report"
+
+	"github.com/gorilla/mux"
+)
+
+// ========== НАПОМИНАНИЯ СОТРУДНИКАМ ==========
+
+func initReminders() {
+	rc := cfg.Reminders
+
+	state, err := reminder.LoadState(rc.StateFile)
+	if err != nil {
+		log.Fatal("Ошибка загрузки напоминаний: ", err)
+	}
+
+	var sender notify.Sender
+	recipient := func(employeeID int) (int64, bool) { return int64(employeeID), true }
+
+	switch rc.Channel {
+	case "vk":
+		sender = notify.NewVKSender(rc.VK.Token, rc.VK.APIURL)
+	case "telegram":
+		sender = notify.NewTelegramSender(rc.Telegram.BotToken, rc.Telegram.APIURL)
+		recipient = func(employeeID int) (int64, bool) {
+			chatID, found := rc.Telegram.Chats[employeeID]
+			return chatID, found
+		}
+	default:
+		log.Fatalf("Неизвестный канал напоминаний: %q (vk или telegram)", rc.Channel)
+	}
+	if rc.DryRun {
+		sender = notify.DryRunSender{Channel: rc.Channel}
+	}
+
+	reminderBot = reminder.NewBot(sender, reminder.Settings{
+		After:     time.Duration(rc.AfterHours) * time.Hour,
+		MaxAge:    time.Duration(rc.MaxAgeHours) * time.Hour,
+		QuietFrom: rc.QuietFrom,
+		QuietTo:   rc.QuietTo,
+		DryRun:    rc.DryRun,
+	}, state)
+
+	reminderBot.Activity = func() (report.EmployeeActivityReport, error) {
+		return getEmployeeActivity(personalPostsCount)
+	}
+	reminderBot.Rule = func(employeeID int) kpi.Rule {
+		rule, _ := kpiStore.Rules().RuleFor(employeeID, employeeRole(employeeID))
+		return rule
+	}
+	reminderBot.Recipient = recipient
+	reminderBot.Link = func(employeeID int) string {
+		if rc.PublicURL == "" {
+			return ""
+		}
+		return strings.TrimRight(rc.PublicURL, "/") + personalLink(employeeID)
+	}
+}
+
+// startReminders запускает проверку постов в фоне, если напоминания включены
+func startReminders() {
+	if !cfg.Reminders.Enabled {
+		return
+	}
+	interval := time.Duration(cfg.Reminders.CheckMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	mode := reminderBot.Sender.Name()
+	fmt.Printf("🔔 Напоминания: %s, через %d ч после поста, проверка каждые %v\n", mode, cfg.Reminders.AfterHours, interval)
+	go reminderBot.Run(context.Background(), interval)
+}
+
+// meRemindersHandler — включение и отключение напоминаний со своей страницы
+func meRemindersHandler(w http.ResponseWriter, r *http.Request) {
+	session, _ := auth.FromContext(r.Context())
+	if session.VKID == 0 {
+		http.Error(w, "К пользователю не привязан VK ID — обратитесь к администратору", http.StatusNotFound)
+		return
+	}
+
+	if !setReminders(w, r, session.VKID) {
+		return
+	}
+	http.Redirect(w, r, "/me", http.StatusSeeOther)
+}
+
+// signedMeRemindersHandler — то же по подписанной ссылке из сообщения
+func signedMeRemindersHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	employeeID, _ := strconv.Atoi(vars["id"])
+
+	if !linkSigner.Verify(employeeID, vars["sig"]) {
+		http.Error(w, "Ссылка недействительна", http.StatusForbidden)
+		return
+	}
+
+	if !setReminders(w, r, employeeID) {
+		return
+	}
+	http.Redirect(w, r, personalLink(employeeID), http.StatusSeeOther)
+}
+
+func setReminders(w http.ResponseWriter, r *http.Request, employeeID int) bool {
+	optOut := r.FormValue("enabled") != "1"
+	if err := reminderBot.State().SetOptOut(employeeID, optOut); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	fmt.Printf("🔔 Напоминания для %d: отключены=%v\n", employeeID, optOut)
+	return true
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"smm-helper/post"
+)
+
+// Analysis — итоги по постам любой площадки. VK и Telegram отчёты строятся поверх него
+type Analysis struct {
+	Count    int          `json:"count"`
+	Posts    []post.Post  `json:"posts"`
+	Totals   post.Metrics `json:"totals"`
+	Averages post.Metrics `json:"averages"`
+}
+
+type Day struct {
+	Date   time.Time    `json:"date"`
+	Posts  int          `json:"posts"`
+	Totals post.Metrics `json:"totals"`
+}
+
+type RangeAnalysis struct {
+	Analysis
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Daily []Day     `json:"daily"`
+}
+
+func Analyze(posts []post.Post) Analysis {
+	a := Analysis{Posts: []post.Post{}}
+	for _, p := range posts {
+		a.Posts = append(a.Posts, p)
+		a.Totals.Add(p.Metrics)
+	}
+	a.Count = len(a.Posts)
+	a.Averages = a.Totals.Divide(a.Count)
+	return a
+}
+
+// AnalyzeRange считает итоги за период [from, to]. В Daily попадают все дни периода,
+// включая дни без постов, в хронологическом порядке
+func AnalyzeRange(from, to time.Time, posts []post.Post) RangeAnalysis {
+	r := RangeAnalysis{Analysis: Analyze(posts), From: from, To: to, Daily: []Day{}}
+
+	byDay := make(map[string]*Day)
+	for day := TruncateDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		r.Daily = append(r.Daily, Day{Date: day})
+	}
+	for i := range r.Daily {
+		byDay[r.Daily[i].Date.Format("2006-01-02")] = &r.Daily[i]
+	}
+	for _, p := range r.Posts {
+		if day, ok := byDay[p.Date.Format("2006-01-02")]; ok {
+			day.Posts++
+			day.Totals.Add(p.Metrics)
+		}
+	}
+	return r
+}
+
+func (r RangeAnalysis) Period() string {
+	return fmt.Sprintf("%s – %s", r.From.Format("02.01.2006"), r.To.Format("02.01.2006"))
+}
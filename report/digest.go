@@ -0,0 +1,133 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"smm-helper/vk"
+)
+
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Сколько постов и сотрудников попадает в дайджест
+const (
+	digestTopPosts    = 5
+	digestLeaderboard = 10
+)
+
+// Change — изменение к прошлому периоду в процентах. Если в прошлом периоде был 0, изменение 0
+type Change struct {
+	Posts    float64 `json:"posts"`
+	Views    float64 `json:"views"`
+	Likes    float64 `json:"likes"`
+	Reposts  float64 `json:"reposts"`
+	Comments float64 `json:"comments"`
+}
+
+type LeaderboardEntry struct {
+	Employee vk.Employee      `json:"employee"`
+	Stats    vk.ActivityStats `json:"stats"`
+}
+
+// Digest — сводка за неделю или месяц для рассылки
+type Digest struct {
+	Period        string             `json:"period"`
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	Count         int                `json:"count"`
+	Totals        Totals             `json:"totals"`
+	PreviousCount int                `json:"previous_count"`
+	Previous      Totals             `json:"previous"`
+	Change        Change             `json:"change"`
+	TopPosts      []PostStat         `json:"top_posts"`
+	Leaderboard   []LeaderboardEntry `json:"leaderboard"`
+}
+
+// PeriodBounds возвращает последний завершённый период перед now и период до него.
+// Неделя — с понедельника по воскресенье, месяц — календарный
+func PeriodBounds(period string, now time.Time) (from, to, prevFrom, prevTo time.Time, err error) {
+	today := TruncateDay(now)
+	switch period {
+	case PeriodWeek:
+		weekday := (int(today.Weekday()) + 6) % 7 // понедельник = 0
+		thisWeek := today.AddDate(0, 0, -weekday)
+		from, prevFrom = thisWeek.AddDate(0, 0, -7), thisWeek.AddDate(0, 0, -14)
+	case PeriodMonth:
+		thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		from, prevFrom = thisMonth.AddDate(0, -1, 0), thisMonth.AddDate(0, -2, 0)
+	default:
+		return from, to, prevFrom, prevTo, fmt.Errorf("неизвестный период %q (week или month)", period)
+	}
+	// Концы периодов — последняя секунда дня, как в отчёте за период
+	to = nextPeriod(period, from).Add(-time.Second)
+	prevTo = from.Add(-time.Second)
+	return from, to, prevFrom, prevTo, nil
+}
+
+func nextPeriod(period string, from time.Time) time.Time {
+	if period == PeriodWeek {
+		return from.AddDate(0, 0, 7)
+	}
+	return from.AddDate(0, 1, 0)
+}
+
+// BuildDigest собирает дайджест. activity — активность сотрудников по постам текущего периода
+func BuildDigest(ownerID int, period string, from, to time.Time, posts, previous []vk.Post, activity EmployeeActivityReport) Digest {
+	current := BuildPostStats(ownerID, posts)
+	prev := BuildPostStats(ownerID, previous)
+
+	digest := Digest{
+		Period:        period,
+		From:          from,
+		To:            to,
+		Count:         current.Count,
+		Totals:        current.Totals,
+		PreviousCount: prev.Count,
+		Previous:      prev.Totals,
+		Change: Change{
+			Posts:    change(current.Count, prev.Count),
+			Views:    change(current.Totals.Views, prev.Totals.Views),
+			Likes:    change(current.Totals.Likes, prev.Totals.Likes),
+			Reposts:  change(current.Totals.Reposts, prev.Totals.Reposts),
+			Comments: change(current.Totals.Comments, prev.Totals.Comments),
+		},
+		TopPosts:    current.Posts,
+		Leaderboard: []LeaderboardEntry{},
+	}
+
+	sort.SliceStable(digest.TopPosts, func(i, j int) bool {
+		return digest.TopPosts[i].Views > digest.TopPosts[j].Views
+	})
+	if len(digest.TopPosts) > digestTopPosts {
+		digest.TopPosts = digest.TopPosts[:digestTopPosts]
+	}
+
+	// Сотрудники уже отсортированы по активности
+	for _, e := range activity.Employees {
+		if len(digest.Leaderboard) == digestLeaderboard {
+			break
+		}
+		digest.Leaderboard = append(digest.Leaderboard, LeaderboardEntry{Employee: e.Employee, Stats: e.Stats})
+	}
+	return digest
+}
+
+func (d Digest) Title() string {
+	name := "Недельный"
+	if d.Period == PeriodMonth {
+		name = "Месячный"
+	}
+	return fmt.Sprintf("%s дайджест: %s – %s", name, d.From.Format("02.01.2006"), d.To.Format("02.01.2006"))
+}
+
+func change(current, previous int) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return math.Round(float64(current-previous)/float64(previous)*1000) / 10
+}
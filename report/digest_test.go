@@ -0,0 +1,57 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+	endOf := func(y int, m time.Month, d int) time.Time {
+		return date(y, m, d).AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	tests := []struct {
+		name                     string
+		period                   string
+		now                      time.Time
+		from, to, prevFrom, prev time.Time
+	}{
+		// 4 марта 2024 — понедельник
+		{"неделя, понедельник утром", PeriodWeek, date(2024, 3, 4).Add(9 * time.Hour),
+			date(2024, 2, 26), endOf(2024, 3, 3), date(2024, 2, 19), endOf(2024, 2, 25)},
+		{"неделя, воскресенье вечером", PeriodWeek, date(2024, 3, 10).Add(23 * time.Hour),
+			date(2024, 2, 26), endOf(2024, 3, 3), date(2024, 2, 19), endOf(2024, 2, 25)},
+		{"неделя через границу года", PeriodWeek, date(2024, 1, 3),
+			date(2023, 12, 25), endOf(2023, 12, 31), date(2023, 12, 18), endOf(2023, 12, 24)},
+		{"месяц, первое число", PeriodMonth, date(2024, 3, 1).Add(9 * time.Hour),
+			date(2024, 2, 1), endOf(2024, 2, 29), date(2024, 1, 1), endOf(2024, 1, 31)},
+		{"месяц, последний день", PeriodMonth, date(2024, 3, 31).Add(23 * time.Hour),
+			date(2024, 2, 1), endOf(2024, 2, 29), date(2024, 1, 1), endOf(2024, 1, 31)},
+		{"месяц через границу года", PeriodMonth, date(2024, 1, 15),
+			date(2023, 12, 1), endOf(2023, 12, 31), date(2023, 11, 1), endOf(2023, 11, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, prevFrom, prevTo, err := PeriodBounds(tt.period, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []time.Time{from, to, prevFrom, prevTo}
+			want := []time.Time{tt.from, tt.to, tt.prevFrom, tt.prev}
+			for i := range got {
+				if !got[i].Equal(want[i]) {
+					t.Errorf("PeriodBounds(%s, %s) = %v, ожидалось %v", tt.period, tt.now.Format("02.01.2006 15:04"), got, want)
+					break
+				}
+			}
+		})
+	}
+
+	if _, _, _, _, err := PeriodBounds("day", time.Now()); err == nil {
+		t.Error("ожидалась ошибка для неизвестного периода")
+	}
+}
@@ -0,0 +1,188 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"smm-helper/post"
+)
+
+// Кросспостом считаются посты разных площадок с похожим текстом, вышедшие не дальше MatchWindow
+const (
+	MatchWindow   = 24 * time.Hour
+	MinSimilarity = 0.5
+)
+
+// Match — одна новость в VK и Telegram
+type Match struct {
+	VK         post.Post `json:"vk"`
+	Telegram   post.Post `json:"telegram"`
+	Similarity float64   `json:"similarity"` // 0..1
+}
+
+// Gap — на сколько Telegram пост вышел позже VK (отрицательный — раньше)
+func (m Match) Gap() time.Duration {
+	return m.Telegram.Date.Sub(m.VK.Date)
+}
+
+// Leader — площадка, где у новости больше просмотров
+func (m Match) Leader() post.Platform {
+	if m.Telegram.Metrics.Views > m.VK.Metrics.Views {
+		return post.Telegram
+	}
+	return post.VK
+}
+
+func (m Match) SimilarityPercent() int {
+	return int(math.Round(m.Similarity * 100))
+}
+
+func (m Match) ShortText() string {
+	return preview(m.VK.Text)
+}
+
+// Delay — разница во времени выхода, например «+1 ч 20 мин»: плюс — Telegram позже
+func (m Match) Delay() string {
+	gap := m.Gap().Round(time.Minute)
+	sign := "+"
+	if gap < 0 {
+		sign, gap = "−", -gap
+	}
+	hours, minutes := int(gap.Hours()), int(gap.Minutes())%60
+	switch {
+	case gap == 0:
+		return "одновременно"
+	case hours == 0:
+		return fmt.Sprintf("%s%d мин", sign, minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%s%d ч", sign, hours)
+	}
+	return fmt.Sprintf("%s%d ч %d мин", sign, hours, minutes)
+}
+
+// Overview — сводка по обеим площадкам за период
+type Overview struct {
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	VK       RangeAnalysis `json:"vk"`
+	Telegram RangeAnalysis `json:"telegram"`
+	Matches  []Match       `json:"matches"` // новые первыми
+	OnlyVK   []post.Post   `json:"only_vk"`
+	OnlyTG   []post.Post   `json:"only_telegram"`
+}
+
+func (o Overview) Period() string {
+	return o.VK.Period()
+}
+
+func BuildOverview(from, to time.Time, vkPosts, tgPosts []post.Post) Overview {
+	matches, onlyVK, onlyTG := MatchCrossPosts(vkPosts, tgPosts, MatchWindow, MinSimilarity)
+	return Overview{
+		From:     from,
+		To:       to,
+		VK:       AnalyzeRange(from, to, vkPosts),
+		Telegram: AnalyzeRange(from, to, tgPosts),
+		Matches:  matches,
+		OnlyVK:   onlyVK,
+		OnlyTG:   onlyTG,
+	}
+}
+
+// MatchCrossPosts сопоставляет посты VK и Telegram один к одному. Сначала берутся пары
+// с самым похожим текстом, при равенстве — ближайшие по времени
+func MatchCrossPosts(vkPosts, tgPosts []post.Post, window time.Duration, minSimilarity float64) (matches []Match, onlyVK, onlyTG []post.Post) {
+	tgWords := make([]map[string]bool, len(tgPosts))
+	for j, p := range tgPosts {
+		tgWords[j] = words(p.Text)
+	}
+
+	type candidate struct {
+		vk, tg     int
+		similarity float64
+		gap        time.Duration
+	}
+	candidates := []candidate{}
+	for i, v := range vkPosts {
+		vkWords := words(v.Text)
+		for j, t := range tgPosts {
+			gap := t.Date.Sub(v.Date)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > window {
+				continue
+			}
+			if s := jaccard(vkWords, tgWords[j]); s >= minSimilarity {
+				candidates = append(candidates, candidate{vk: i, tg: j, similarity: s, gap: gap})
+			}
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].similarity != candidates[b].similarity {
+			return candidates[a].similarity > candidates[b].similarity
+		}
+		return candidates[a].gap < candidates[b].gap
+	})
+
+	usedVK := map[int]bool{}
+	usedTG := map[int]bool{}
+	matches = []Match{}
+	for _, c := range candidates {
+		if usedVK[c.vk] || usedTG[c.tg] {
+			continue
+		}
+		usedVK[c.vk] = true
+		usedTG[c.tg] = true
+		matches = append(matches, Match{VK: vkPosts[c.vk], Telegram: tgPosts[c.tg], Similarity: c.similarity})
+	}
+	sort.Slice(matches, func(a, b int) bool { return matches[a].VK.Date.After(matches[b].VK.Date) })
+
+	onlyVK = []post.Post{}
+	for i, p := range vkPosts {
+		if !usedVK[i] {
+			onlyVK = append(onlyVK, p)
+		}
+	}
+	onlyTG = []post.Post{}
+	for j, p := range tgPosts {
+		if !usedTG[j] {
+			onlyTG = append(onlyTG, p)
+		}
+	}
+	return matches, onlyVK, onlyTG
+}
+
+// words — слова текста без регистра, ссылок и знаков. Короткие слова не учитываются
+func words(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '/' && r != '.'
+	}) {
+		if strings.Contains(w, "/") || strings.HasPrefix(w, "http") {
+			continue
+		}
+		w = strings.Trim(w, ".")
+		if len([]rune(w)) >= 3 {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+// jaccard — доля общих слов. Посты без текста (только медиа) не сопоставляются
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
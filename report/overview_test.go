@@ -0,0 +1,117 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"smm-helper/post"
+)
+
+func platformPost(platform post.Platform, id int, date time.Time, text string, views int) post.Post {
+	return post.Post{Platform: platform, ID: id, Date: date, Text: text, Metrics: post.Metrics{Views: views, Reactions: views / 10}}
+}
+
+func TestMatchCrossPosts(t *testing.T) {
+	base := time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local)
+	news := "Студенты колледжа заняли первое место на региональной олимпиаде по программированию"
+	vkPosts := []post.Post{
+		platformPost(post.VK, 1, base, news+" https://vk.cc/abc", 500),
+		platformPost(post.VK, 2, base.Add(2*time.Hour), "Расписание занятий на следующую неделю опубликовано", 300),
+		platformPost(post.VK, 3, base.Add(3*time.Hour), "Объявление для родителей первокурсников", 100),
+	}
+	tgPosts := []post.Post{
+		// Та же новость через час, другая ссылка и пунктуация
+		platformPost(post.Telegram, 11, base.Add(time.Hour), news+"! https://t.me/kait", 800),
+		// Тот же текст, но через двое суток — не кросспост
+		platformPost(post.Telegram, 12, base.Add(50*time.Hour), "Расписание занятий на следующую неделю опубликовано", 200),
+		// Только медиа, без текста
+		platformPost(post.Telegram, 13, base.Add(3*time.Hour), "", 50),
+	}
+
+	matches, onlyVK, onlyTG := MatchCrossPosts(vkPosts, tgPosts, MatchWindow, MinSimilarity)
+
+	if len(matches) != 1 || matches[0].VK.ID != 1 || matches[0].Telegram.ID != 11 {
+		t.Fatalf("совпадения %+v, ожидалась пара VK 1 — Telegram 11", matches)
+	}
+	m := matches[0]
+	if m.SimilarityPercent() != 100 || m.Leader() != post.Telegram || m.Delay() != "+1 ч" {
+		t.Errorf("сходство %d%%, лидер %s, задержка %s", m.SimilarityPercent(), m.Leader(), m.Delay())
+	}
+	if len(onlyVK) != 2 || onlyVK[0].ID != 2 || onlyVK[1].ID != 3 {
+		t.Errorf("только VK: %+v", onlyVK)
+	}
+	if len(onlyTG) != 2 || onlyTG[0].ID != 12 || onlyTG[1].ID != 13 {
+		t.Errorf("только Telegram: %+v", onlyTG)
+	}
+}
+
+// Каждый пост участвует не больше чем в одной паре: выигрывает самый похожий текст
+func TestMatchCrossPostsOneToOne(t *testing.T) {
+	base := time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local)
+	vkPosts := []post.Post{
+		platformPost(post.VK, 1, base, "Открытые двери в субботу приглашаем абитуриентов", 0),
+		platformPost(post.VK, 2, base.Add(time.Hour), "Открытые двери в субботу приглашаем абитуриентов и родителей", 0),
+	}
+	tgPosts := []post.Post{
+		platformPost(post.Telegram, 11, base.Add(2*time.Hour), "Открытые двери в субботу приглашаем абитуриентов и родителей", 0),
+	}
+
+	matches, onlyVK, _ := MatchCrossPosts(vkPosts, tgPosts, MatchWindow, MinSimilarity)
+	if len(matches) != 1 || matches[0].VK.ID != 2 {
+		t.Fatalf("совпадения %+v, ожидался VK 2 с полным совпадением текста", matches)
+	}
+	if len(onlyVK) != 1 || onlyVK[0].ID != 1 {
+		t.Errorf("только VK: %+v", onlyVK)
+	}
+}
+
+func TestMatchDelay(t *testing.T) {
+	base := time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		gap  time.Duration
+		want string
+	}{
+		{0, "одновременно"},
+		{20 * time.Second, "одновременно"},
+		{45 * time.Minute, "+45 мин"},
+		{2 * time.Hour, "+2 ч"},
+		{80 * time.Minute, "+1 ч 20 мин"},
+		{-90 * time.Minute, "−1 ч 30 мин"},
+	}
+	for _, tt := range tests {
+		m := Match{VK: post.Post{Date: base}, Telegram: post.Post{Date: base.Add(tt.gap)}}
+		if got := m.Delay(); got != tt.want {
+			t.Errorf("Delay(%v) = %q, ожидалось %q", tt.gap, got, tt.want)
+		}
+	}
+}
+
+func TestBuildOverview(t *testing.T) {
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 3, 5, 23, 59, 59, 0, time.Local)
+	vkPosts := []post.Post{
+		platformPost(post.VK, 1, from.Add(10*time.Hour), "Первая новость недели о победе в олимпиаде", 500),
+		platformPost(post.VK, 2, from.Add(34*time.Hour), "Вторая новость только для VK", 100),
+	}
+	tgPosts := []post.Post{
+		platformPost(post.Telegram, 11, from.Add(11*time.Hour), "Первая новость недели о победе в олимпиаде", 300),
+	}
+
+	o := BuildOverview(from, to, vkPosts, tgPosts)
+	if o.VK.Totals.Views != 600 || o.Telegram.Totals.Views != 300 {
+		t.Errorf("просмотры VK %d, Telegram %d", o.VK.Totals.Views, o.Telegram.Totals.Views)
+	}
+	if o.VK.Totals.Engagement() != 60 || o.Telegram.Totals.Engagement() != 30 {
+		t.Errorf("вовлечённость VK %d, Telegram %d", o.VK.Totals.Engagement(), o.Telegram.Totals.Engagement())
+	}
+	// Дни обеих площадок совпадают — по ним строятся графики сводки
+	if len(o.VK.Daily) != 2 || len(o.Telegram.Daily) != 2 || o.Telegram.Daily[1].Posts != 0 {
+		t.Errorf("дни VK %+v, Telegram %+v", o.VK.Daily, o.Telegram.Daily)
+	}
+	if len(o.Matches) != 1 || len(o.OnlyVK) != 1 || len(o.OnlyTG) != 0 {
+		t.Errorf("совпадений %d, только VK %d, только Telegram %d", len(o.Matches), len(o.OnlyVK), len(o.OnlyTG))
+	}
+	if o.Period() != "04.03.2024 – 05.03.2024" {
+		t.Errorf("Period() = %q", o.Period())
+	}
+}
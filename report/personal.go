@@ -0,0 +1,62 @@
+package report
+
+// MissedPost — пост, где сотрудник не поставил лайк и/или не сделал репост
+type MissedPost struct {
+	PostRef
+	Liked    bool `json:"liked"`
+	Reposted bool `json:"reposted"`
+}
+
+// PersonalReport — страница «что я пропустил» для одного сотрудника
+type PersonalReport struct {
+	EmployeeActivity
+	Missed     []MissedPost `json:"missed"`
+	Streak     int          `json:"streak"`
+	Rank       int          `json:"rank"`
+	RankOf     int          `json:"rank_of"`
+	PostsCount int          `json:"posts_count"`
+}
+
+// BuildPersonalReport строится поверх общего отчёта активности.
+// Streak — сколько последних постов подряд отработано полностью (лайк и репост).
+// Rank — место по сумме лайков и репостов, при равенстве место общее
+func BuildPersonalReport(activity EmployeeActivityReport, employeeID int) (PersonalReport, bool) {
+	for _, e := range activity.Employees {
+		if e.Employee.ID != employeeID {
+			continue
+		}
+
+		personal := PersonalReport{
+			EmployeeActivity: e,
+			Missed:           []MissedPost{},
+			RankOf:           len(activity.Employees),
+			PostsCount:       len(activity.Posts),
+			Rank:             1,
+		}
+
+		// Посты идут от новых к старым, поэтому серия считается с начала
+		streakOpen := true
+		for i, a := range e.Activity {
+			done := a.Liked && a.Reposted
+			if done && streakOpen {
+				personal.Streak++
+			}
+			if !done {
+				streakOpen = false
+				personal.Missed = append(personal.Missed, MissedPost{
+					PostRef:  activity.Posts[i],
+					Liked:    a.Liked,
+					Reposted: a.Reposted,
+				})
+			}
+		}
+
+		for _, other := range activity.Employees {
+			if other.Stats.Total > e.Stats.Total {
+				personal.Rank++
+			}
+		}
+		return personal, true
+	}
+	return PersonalReport{}, false
+}
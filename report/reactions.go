@@ -0,0 +1,216 @@
+package report
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"smm-helper/post"
+)
+
+// Рубрика поста — первый хештег в тексте
+const NoRubric = "без рубрики"
+
+var hashtagRe = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+
+// ReactionShare — сколько раз поставили эмодзи и какая это доля от всех реакций группы
+type ReactionShare struct {
+	Emoji   string  `json:"emoji"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// Label — эмодзи для показа. Свои эмодзи канала приходят как числовой ID
+func (s ReactionShare) Label() string {
+	return ReactionLabel(s.Emoji)
+}
+
+type ReactionGroup struct {
+	Posts     int             `json:"posts"`
+	Total     int             `json:"total"`
+	Reactions []ReactionShare `json:"reactions"` // по убыванию
+}
+
+// Top — самая частая реакция группы
+func (g ReactionGroup) Top() ReactionShare {
+	if len(g.Reactions) == 0 {
+		return ReactionShare{}
+	}
+	return g.Reactions[0]
+}
+
+// Share — доля эмодзи в группе, 0 если его не ставили
+func (g ReactionGroup) Share(emoji string) float64 {
+	for _, r := range g.Reactions {
+		if r.Emoji == emoji {
+			return r.Percent
+		}
+	}
+	return 0
+}
+
+func (g ReactionGroup) count(emoji string) int {
+	for _, r := range g.Reactions {
+		if r.Emoji == emoji {
+			return r.Count
+		}
+	}
+	return 0
+}
+
+type RubricReactions struct {
+	Rubric string `json:"rubric"`
+	ReactionGroup
+}
+
+type WeekReactions struct {
+	Week time.Time `json:"week"` // понедельник недели
+	ReactionGroup
+}
+
+type ReactionsReport struct {
+	Overall ReactionGroup     `json:"overall"`
+	Rubrics []RubricReactions `json:"rubrics"` // по убыванию числа реакций
+	Weeks   []WeekReactions   `json:"weeks"`   // по времени, включая недели без постов
+	// Emojis — самые частые эмодзи, столбцы таблиц и серии графика
+	Emojis []ReactionShare `json:"emojis"`
+}
+
+// Rubric — первый хештег поста в нижнем регистре
+func Rubric(text string) string {
+	tag := hashtagRe.FindString(text)
+	if tag == "" {
+		return NoRubric
+	}
+	return strings.ToLower(tag)
+}
+
+// ReactionLabel — эмодзи как есть, свой эмодзи канала — значком
+func ReactionLabel(emoji string) string {
+	if _, err := strconv.ParseInt(emoji, 10, 64); err == nil {
+		return "🧩"
+	}
+	if emoji == "" {
+		return "?"
+	}
+	return emoji
+}
+
+// BuildReactionsReport раскладывает реакции постов по эмодзи: в целом, по рубрикам и по неделям.
+// Работает для любой площадки: у VK единственная реакция — лайк.
+// top — сколько самых частых эмодзи показывать отдельно
+func BuildReactionsReport(posts []post.Post, top int) ReactionsReport {
+	overall := map[string]int{}
+	rubrics := map[string]map[string]int{}
+	rubricPosts := map[string]int{}
+	weeks := map[time.Time]map[string]int{}
+	weekPosts := map[time.Time]int{}
+
+	var first, last time.Time
+	for _, p := range posts {
+		rubric := Rubric(p.Text)
+		week := weekStart(p.Date)
+		if first.IsZero() || week.Before(first) {
+			first = week
+		}
+		if week.After(last) {
+			last = week
+		}
+
+		if rubrics[rubric] == nil {
+			rubrics[rubric] = map[string]int{}
+		}
+		if weeks[week] == nil {
+			weeks[week] = map[string]int{}
+		}
+		rubricPosts[rubric]++
+		weekPosts[week]++
+
+		for _, r := range p.ByEmoji {
+			overall[r.Emoji] += r.Count
+			rubrics[rubric][r.Emoji] += r.Count
+			weeks[week][r.Emoji] += r.Count
+		}
+	}
+
+	report := ReactionsReport{
+		Overall: newReactionGroup(len(posts), overall),
+		Rubrics: []RubricReactions{},
+		Weeks:   []WeekReactions{},
+		Emojis:  []ReactionShare{},
+	}
+	for i, r := range report.Overall.Reactions {
+		if i >= top {
+			break
+		}
+		report.Emojis = append(report.Emojis, r)
+	}
+
+	for rubric, counts := range rubrics {
+		report.Rubrics = append(report.Rubrics, RubricReactions{
+			Rubric:        rubric,
+			ReactionGroup: newReactionGroup(rubricPosts[rubric], counts),
+		})
+	}
+	sort.Slice(report.Rubrics, func(i, j int) bool {
+		a, b := report.Rubrics[i], report.Rubrics[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Rubric < b.Rubric
+	})
+
+	if !first.IsZero() {
+		for week := first; !week.After(last); week = week.AddDate(0, 0, 7) {
+			report.Weeks = append(report.Weeks, WeekReactions{
+				Week:          week,
+				ReactionGroup: newReactionGroup(weekPosts[week], weeks[week]),
+			})
+		}
+	}
+	return report
+}
+
+// WeekCounts — число реакций эмодзи по неделям, для графика
+func (r ReactionsReport) WeekCounts(emoji string) []int {
+	values := make([]int, len(r.Weeks))
+	for i, w := range r.Weeks {
+		values[i] = w.count(emoji)
+	}
+	return values
+}
+
+func newReactionGroup(posts int, counts map[string]int) ReactionGroup {
+	g := ReactionGroup{Posts: posts, Reactions: []ReactionShare{}}
+	for _, c := range counts {
+		g.Total += c
+	}
+	for emoji, c := range counts {
+		if c == 0 {
+			continue
+		}
+		g.Reactions = append(g.Reactions, ReactionShare{
+			Emoji:   emoji,
+			Count:   c,
+			Percent: math.Round(float64(c)*1000/float64(g.Total)) / 10,
+		})
+	}
+	sort.Slice(g.Reactions, func(i, j int) bool {
+		a, b := g.Reactions[i], g.Reactions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Emoji < b.Emoji
+	})
+	return g
+}
+
+// weekStart — понедельник недели в 00:00
+func weekStart(t time.Time) time.Time {
+	day := TruncateDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"smm-helper/post"
+)
+
+func reactionPost(date time.Time, text string, reactions ...post.Reaction) post.Post {
+	return post.Post{Platform: post.Telegram, Date: date, Text: text, ByEmoji: reactions}
+}
+
+func TestRubric(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"#Новости колледжа и #спорт", "#новости"},
+		{"Итоги недели\n\n#итоги_недели", "#итоги_недели"},
+		{"Без хештегов", NoRubric},
+		{"", NoRubric},
+	}
+	for _, tt := range tests {
+		if got := Rubric(tt.text); got != tt.want {
+			t.Errorf("Rubric(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReactionLabel(t *testing.T) {
+	tests := map[string]string{"👍": "👍", "5368324170671202286": "🧩", "": "?"}
+	for emoji, want := range tests {
+		if got := ReactionLabel(emoji); got != want {
+			t.Errorf("ReactionLabel(%q) = %q, ожидалось %q", emoji, got, want)
+		}
+	}
+}
+
+func TestBuildReactionsReport(t *testing.T) {
+	// 4 и 18 марта 2024 — понедельники, неделя 11 марта без постов
+	week1 := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	week3 := week1.AddDate(0, 0, 14)
+	posts := []post.Post{
+		reactionPost(week1.Add(10*time.Hour), "#новости Открытие", post.Reaction{Emoji: "👍", Count: 6}, post.Reaction{Emoji: "🔥", Count: 2}),
+		reactionPost(week1.AddDate(0, 0, 6).Add(20*time.Hour), "#спорт Победа", post.Reaction{Emoji: "🔥", Count: 6}),
+		reactionPost(week3.Add(9*time.Hour), "Без рубрики", post.Reaction{Emoji: "👍", Count: 3}, post.Reaction{Emoji: "😢", Count: 1}),
+	}
+
+	r := BuildReactionsReport(posts, 2)
+
+	if r.Overall.Posts != 3 || r.Overall.Total != 18 {
+		t.Errorf("Overall: постов %d, реакций %d", r.Overall.Posts, r.Overall.Total)
+	}
+	// 👍 и 🔥 по 9 — при равенстве по эмодзи
+	wantOverall := []ReactionShare{{"👍", 9, 50}, {"🔥", 8, 44.4}, {"😢", 1, 5.6}}
+	if len(r.Overall.Reactions) != len(wantOverall) {
+		t.Fatalf("Overall.Reactions = %+v", r.Overall.Reactions)
+	}
+	for i, w := range wantOverall {
+		if r.Overall.Reactions[i] != w {
+			t.Errorf("Overall.Reactions[%d] = %+v, ожидалось %+v", i, r.Overall.Reactions[i], w)
+		}
+	}
+	if r.Overall.Top().Emoji != "👍" || r.Overall.Share("🔥") != 44.4 || r.Overall.Share("❤") != 0 {
+		t.Errorf("Top() = %+v, Share(🔥) = %v", r.Overall.Top(), r.Overall.Share("🔥"))
+	}
+	if len(r.Emojis) != 2 || r.Emojis[0].Emoji != "👍" || r.Emojis[1].Emoji != "🔥" {
+		t.Errorf("Emojis = %+v, ожидались два самых частых", r.Emojis)
+	}
+
+	// Рубрики по убыванию реакций, при равенстве — по имени
+	wantRubrics := []struct {
+		rubric string
+		total  int
+	}{{"#новости", 8}, {"#спорт", 6}, {NoRubric, 4}}
+	if len(r.Rubrics) != len(wantRubrics) {
+		t.Fatalf("Rubrics = %+v", r.Rubrics)
+	}
+	for i, w := range wantRubrics {
+		if r.Rubrics[i].Rubric != w.rubric || r.Rubrics[i].Total != w.total || r.Rubrics[i].Posts != 1 {
+			t.Errorf("Rubrics[%d] = %s %d, ожидалось %s %d", i, r.Rubrics[i].Rubric, r.Rubrics[i].Total, w.rubric, w.total)
+		}
+	}
+
+	// Недели подряд, включая пустую
+	if len(r.Weeks) != 3 || !r.Weeks[1].Week.Equal(week1.AddDate(0, 0, 7)) || r.Weeks[1].Posts != 0 {
+		t.Fatalf("Weeks = %+v", r.Weeks)
+	}
+	if got := r.WeekCounts("🔥"); len(got) != 3 || got[0] != 8 || got[1] != 0 || got[2] != 0 {
+		t.Errorf("WeekCounts(🔥) = %v, ожидалось [8 0 0]", got)
+	}
+}
+
+func TestBuildReactionsReportEmpty(t *testing.T) {
+	r := BuildReactionsReport(nil, 5)
+	if r.Overall.Total != 0 || r.Overall.Reactions == nil || r.Rubrics == nil || r.Weeks == nil || r.Emojis == nil {
+		t.Errorf("пустой отчёт %+v, в JSON должны быть пустые массивы", r)
+	}
+	if top := r.Overall.Top(); top != (ReactionShare{}) {
+		t.Errorf("Top() = %+v", top)
+	}
+}
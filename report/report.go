@@ -0,0 +1,235 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"smm-helper/post"
+	"smm-helper/vk"
+)
+
+// Длина превью текста поста в таблицах
+const previewLength = 150
+
+type PostStat struct {
+	ID       int       `json:"id"`
+	Date     time.Time `json:"date"`
+	Link     string    `json:"link"`
+	Text     string    `json:"text"`
+	Views    int       `json:"views"`
+	Likes    int       `json:"likes"`
+	Reposts  int       `json:"reposts"`
+	Comments int       `json:"comments"`
+}
+
+type Totals struct {
+	Views    int `json:"views"`
+	Likes    int `json:"likes"`
+	Reposts  int `json:"reposts"`
+	Comments int `json:"comments"`
+}
+
+type PostStats struct {
+	Count  int        `json:"count"`
+	Posts  []PostStat `json:"posts"`
+	Totals Totals     `json:"totals"`
+}
+
+type DayStat struct {
+	Date   time.Time `json:"date"`
+	Posts  int       `json:"posts"`
+	Totals Totals    `json:"totals"`
+}
+
+type RangeReport struct {
+	PostStats
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Averages Totals    `json:"averages"`
+	Daily    []DayStat `json:"daily"`
+}
+
+type PostRef struct {
+	ID   int       `json:"id"`
+	Date time.Time `json:"date"`
+	Link string    `json:"link"`
+	Text string    `json:"text"`
+}
+
+type PostActivity struct {
+	PostID   int  `json:"post_id"`
+	Liked    bool `json:"liked"`
+	Reposted bool `json:"reposted"`
+}
+
+type EmployeeActivity struct {
+	Employee vk.Employee      `json:"employee"`
+	Activity []PostActivity   `json:"activity"`
+	Stats    vk.ActivityStats `json:"stats"`
+}
+
+type EmployeeActivityReport struct {
+	Posts     []PostRef          `json:"posts"`
+	Employees []EmployeeActivity `json:"employees"`
+}
+
+func PostLink(ownerID, postID int) string {
+	return post.VKLink(ownerID, postID)
+}
+
+func NewPostStat(ownerID int, p vk.Post) PostStat {
+	return vkPostStat(post.FromVK(ownerID, p))
+}
+
+// vkPostStat — общий пост в терминах VK: реакции — это лайки, Shares — репосты
+func vkPostStat(p post.Post) PostStat {
+	return PostStat{
+		ID:       p.ID,
+		Date:     p.Date,
+		Link:     p.Link,
+		Text:     p.Text,
+		Views:    p.Metrics.Views,
+		Likes:    p.Metrics.Reactions,
+		Reposts:  p.Metrics.Shares,
+		Comments: p.Metrics.Comments,
+	}
+}
+
+func vkTotals(m post.Metrics) Totals {
+	return Totals{Views: m.Views, Likes: m.Reactions, Reposts: m.Shares, Comments: m.Comments}
+}
+
+func vkPostStats(a Analysis) PostStats {
+	stats := PostStats{Count: a.Count, Posts: []PostStat{}, Totals: vkTotals(a.Totals)}
+	for _, p := range a.Posts {
+		stats.Posts = append(stats.Posts, vkPostStat(p))
+	}
+	return stats
+}
+
+func (p PostStat) ShortText() string {
+	return preview(p.Text)
+}
+
+func (p PostRef) ShortText() string {
+	return preview(p.Text)
+}
+
+// preview обрезает текст по символам, а не по байтам, чтобы не резать кириллицу
+func preview(text string) string {
+	runes := []rune(text)
+	if len(runes) > previewLength {
+		return string(runes[:previewLength]) + "..."
+	}
+	return text
+}
+
+func BuildPostStats(ownerID int, posts []vk.Post) PostStats {
+	return vkPostStats(Analyze(post.FromVKPosts(ownerID, posts)))
+}
+
+// BuildRangeReport — AnalyzeRange в терминах VK
+func BuildRangeReport(ownerID int, from, to time.Time, posts []vk.Post) RangeReport {
+	a := AnalyzeRange(from, to, post.FromVKPosts(ownerID, posts))
+	report := RangeReport{
+		PostStats: vkPostStats(a.Analysis),
+		From:      from,
+		To:        to,
+		Averages:  vkTotals(a.Averages),
+		Daily:     []DayStat{},
+	}
+	for _, d := range a.Daily {
+		report.Daily = append(report.Daily, DayStat{Date: d.Date, Posts: d.Posts, Totals: vkTotals(d.Totals)})
+	}
+	return report
+}
+
+func (r RangeReport) Period() string {
+	return fmt.Sprintf("%s – %s", r.From.Format("02.01.2006"), r.To.Format("02.01.2006"))
+}
+
+// BuildEmployeeActivity сопоставляет лайки и репосты каждого поста с сотрудниками.
+// Сотрудники отсортированы по убыванию суммарной активности
+func BuildEmployeeActivity(ownerID int, posts []vk.Post, employees map[int]vk.Employee, likes, reposts map[int][]int) EmployeeActivityReport {
+	report := EmployeeActivityReport{Posts: []PostRef{}, Employees: []EmployeeActivity{}}
+
+	likeSets := make(map[int]map[int]bool)
+	repostSets := make(map[int]map[int]bool)
+	for _, p := range posts {
+		report.Posts = append(report.Posts, PostRef{
+			ID:   p.ID,
+			Date: time.Unix(int64(p.Date), 0),
+			Link: PostLink(ownerID, p.ID),
+			Text: p.Text,
+		})
+		likeSets[p.ID] = toSet(likes[p.ID])
+		repostSets[p.ID] = toSet(reposts[p.ID])
+	}
+
+	for empID, emp := range employees {
+		item := EmployeeActivity{Employee: emp, Activity: []PostActivity{}}
+		for _, p := range posts {
+			a := PostActivity{
+				PostID:   p.ID,
+				Liked:    likeSets[p.ID][empID],
+				Reposted: repostSets[p.ID][empID],
+			}
+			if a.Liked {
+				item.Stats.Likes++
+			}
+			if a.Reposted {
+				item.Stats.Reposts++
+			}
+			item.Activity = append(item.Activity, a)
+		}
+		item.Stats.Total = item.Stats.Likes + item.Stats.Reposts
+		report.Employees = append(report.Employees, item)
+	}
+
+	sort.SliceStable(report.Employees, func(i, j int) bool {
+		a, b := report.Employees[i], report.Employees[j]
+		if a.Stats.Total != b.Stats.Total {
+			return a.Stats.Total > b.Stats.Total
+		}
+		return a.Employee.Name < b.Employee.Name
+	})
+	return report
+}
+
+// Only оставляет в отчёте одного сотрудника (для роли employee)
+func (r EmployeeActivityReport) Only(employeeID int) EmployeeActivityReport {
+	filtered := EmployeeActivityReport{Posts: r.Posts, Employees: []EmployeeActivity{}}
+	for _, e := range r.Employees {
+		if e.Employee.ID == employeeID {
+			filtered.Employees = append(filtered.Employees, e)
+		}
+	}
+	return filtered
+}
+
+// Symbol — значок ячейки в матрице активности
+func (a PostActivity) Symbol() string {
+	switch {
+	case a.Liked && a.Reposted:
+		return "❤️🔁"
+	case a.Liked:
+		return "❤️"
+	case a.Reposted:
+		return "🔁"
+	}
+	return "➖"
+}
+
+func toSet(ids []int) map[int]bool {
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// TruncateDay — начало дня t в его часовом поясе
+func TruncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
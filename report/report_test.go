@@ -0,0 +1,210 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"smm-helper/vk"
+)
+
+const testOwner = -100
+
+func vkPost(id int, date time.Time, views, likes, reposts, comments int) vk.Post {
+	return vk.Post{
+		ID:       id,
+		Date:     int(date.Unix()),
+		Text:     "пост",
+		Views:    vk.Views{Count: views},
+		Likes:    vk.Count{Count: likes},
+		Reposts:  vk.Count{Count: reposts},
+		Comments: vk.Count{Count: comments},
+	}
+}
+
+func TestBuildPostStats(t *testing.T) {
+	day := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		posts  []vk.Post
+		count  int
+		totals Totals
+	}{
+		{"без постов", nil, 0, Totals{}},
+		{"один пост", []vk.Post{vkPost(1, day, 100, 5, 1, 2)}, 1, Totals{Views: 100, Likes: 5, Reposts: 1, Comments: 2}},
+		{"несколько постов", []vk.Post{
+			vkPost(1, day, 100, 5, 1, 2),
+			vkPost(2, day, 50, 3, 0, 1),
+		}, 2, Totals{Views: 150, Likes: 8, Reposts: 1, Comments: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := BuildPostStats(testOwner, tt.posts)
+			if stats.Count != tt.count || len(stats.Posts) != tt.count {
+				t.Errorf("Count = %d, Posts = %d, ожидалось %d", stats.Count, len(stats.Posts), tt.count)
+			}
+			if stats.Posts == nil {
+				t.Error("Posts = nil, в JSON должен быть пустой массив")
+			}
+			if stats.Totals != tt.totals {
+				t.Errorf("Totals = %+v, ожидалось %+v", stats.Totals, tt.totals)
+			}
+		})
+	}
+
+	stats := BuildPostStats(testOwner, []vk.Post{vkPost(7, day, 1, 1, 1, 1)})
+	if want := "https://vk.com/wall-100_7"; stats.Posts[0].Link != want {
+		t.Errorf("Link = %q, ожидалось %q", stats.Posts[0].Link, want)
+	}
+}
+
+func TestBuildRangeReport(t *testing.T) {
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 3, 7, 23, 59, 59, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		posts    []vk.Post
+		averages Totals
+		daily    []int // постов по дням
+	}{
+		{"без постов", nil, Totals{}, []int{0, 0, 0, 0}},
+		{"пустые дни заполнены", []vk.Post{
+			vkPost(1, from.Add(10*time.Hour), 100, 10, 2, 4),
+			vkPost(2, from.Add(11*time.Hour), 51, 5, 1, 1),
+			vkPost(3, from.AddDate(0, 0, 3).Add(20*time.Hour), 30, 0, 0, 1),
+		}, Totals{Views: 60, Likes: 5, Reposts: 1, Comments: 2}, []int{2, 0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := BuildRangeReport(testOwner, from, to, tt.posts)
+			if r.Averages != tt.averages {
+				t.Errorf("Averages = %+v, ожидалось %+v", r.Averages, tt.averages)
+			}
+			if len(r.Daily) != len(tt.daily) {
+				t.Fatalf("дней в Daily = %d, ожидалось %d", len(r.Daily), len(tt.daily))
+			}
+			for i, d := range r.Daily {
+				if want := from.AddDate(0, 0, i); !d.Date.Equal(want) {
+					t.Errorf("Daily[%d].Date = %v, ожидалось %v", i, d.Date, want)
+				}
+				if d.Posts != tt.daily[i] {
+					t.Errorf("Daily[%d].Posts = %d, ожидалось %d", i, d.Posts, tt.daily[i])
+				}
+			}
+		})
+	}
+
+	r := BuildRangeReport(testOwner, from, to, nil)
+	if want := "04.03.2024 – 07.03.2024"; r.Period() != want {
+		t.Errorf("Period() = %q, ожидалось %q", r.Period(), want)
+	}
+}
+
+func TestBuildEmployeeActivity(t *testing.T) {
+	day := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	posts := []vk.Post{vkPost(1, day, 0, 0, 0, 0), vkPost(2, day, 0, 0, 0, 0)}
+	employees := map[int]vk.Employee{
+		10: {ID: 10, Name: "Борис"},
+		11: {ID: 11, Name: "Анна"},
+		12: {ID: 12, Name: "Вера"},
+		13: {ID: 13, Name: "Глеб"},
+	}
+	likes := map[int][]int{1: {10, 11, 12}, 2: {12}}
+	reposts := map[int][]int{1: {12}, 2: {10, 11}}
+
+	r := BuildEmployeeActivity(testOwner, posts, employees, likes, reposts)
+
+	// Вера — 3 действия, Анна и Борис по 2 (по имени), Глеб — 0
+	want := []struct {
+		name  string
+		stats vk.ActivityStats
+	}{
+		{"Вера", vk.ActivityStats{Likes: 2, Reposts: 1, Total: 3}},
+		{"Анна", vk.ActivityStats{Likes: 1, Reposts: 1, Total: 2}},
+		{"Борис", vk.ActivityStats{Likes: 1, Reposts: 1, Total: 2}},
+		{"Глеб", vk.ActivityStats{}},
+	}
+	if len(r.Employees) != len(want) {
+		t.Fatalf("сотрудников %d, ожидалось %d", len(r.Employees), len(want))
+	}
+	for i, w := range want {
+		e := r.Employees[i]
+		if e.Employee.Name != w.name || e.Stats != w.stats {
+			t.Errorf("Employees[%d] = %s %+v, ожидалось %s %+v", i, e.Employee.Name, e.Stats, w.name, w.stats)
+		}
+		if len(e.Activity) != len(posts) {
+			t.Errorf("у %s %d ячеек активности, ожидалось %d", e.Employee.Name, len(e.Activity), len(posts))
+		}
+	}
+	if a := r.Employees[0].Activity[0]; !a.Liked || !a.Reposted || a.Symbol() != "❤️🔁" {
+		t.Errorf("Вера, пост 1: %+v %s", a, a.Symbol())
+	}
+	if len(r.Posts) != 2 || r.Posts[1].Link != "https://vk.com/wall-100_2" {
+		t.Errorf("Posts = %+v", r.Posts)
+	}
+}
+
+func TestOnly(t *testing.T) {
+	r := EmployeeActivityReport{
+		Posts: []PostRef{{ID: 1}},
+		Employees: []EmployeeActivity{
+			{Employee: vk.Employee{ID: 10, Name: "Анна"}},
+			{Employee: vk.Employee{ID: 11, Name: "Борис"}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		id   int
+		want []int
+	}{
+		{"сотрудник есть", 11, []int{11}},
+		{"сотрудника нет", 99, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Only(tt.id)
+			if len(got.Posts) != len(r.Posts) {
+				t.Errorf("Posts = %d, посты не должны фильтроваться", len(got.Posts))
+			}
+			if got.Employees == nil {
+				t.Error("Employees = nil, в JSON должен быть пустой массив")
+			}
+			if len(got.Employees) != len(tt.want) {
+				t.Fatalf("Employees = %d, ожидалось %d", len(got.Employees), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got.Employees[i].Employee.ID != id {
+					t.Errorf("Employees[%d].ID = %d, ожидалось %d", i, got.Employees[i].Employee.ID, id)
+				}
+			}
+		})
+	}
+	if len(r.Employees) != 2 {
+		t.Error("Only изменил исходный отчёт")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	long := strings.Repeat("ж", previewLength+1)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"пустой", "", ""},
+		{"короткий", "Привет", "Привет"},
+		{"ровно по длине", strings.Repeat("ж", previewLength), strings.Repeat("ж", previewLength)},
+		{"кириллица режется по символам", long, strings.Repeat("ж", previewLength) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := preview(tt.text); got != tt.want {
+				t.Errorf("preview() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"smm-helper/post"
+	"smm-helper/tg"
+)
+
+type TGPostStat struct {
+	ID            int       `json:"id"`
+	Date          time.Time `json:"date"`
+	Link          string    `json:"link"`
+	Text          string    `json:"text"`
+	Views         int       `json:"views"`
+	Reactions     int       `json:"reactions"`
+	Forwards      int       `json:"forwards"`
+	Media         string    `json:"media,omitempty"`
+	ForwardedFrom string    `json:"forwarded_from,omitempty"`
+}
+
+type TGTotals struct {
+	Views     int `json:"views"`
+	Reactions int `json:"reactions"`
+	Forwards  int `json:"forwards"`
+}
+
+type TGPostStats struct {
+	Count  int          `json:"count"`
+	Posts  []TGPostStat `json:"posts"`
+	Totals TGTotals     `json:"totals"`
+}
+
+type TGDayStat struct {
+	Date   time.Time `json:"date"`
+	Posts  int       `json:"posts"`
+	Totals TGTotals  `json:"totals"`
+}
+
+type TGRangeReport struct {
+	TGPostStats
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Averages TGTotals    `json:"averages"`
+	Daily    []TGDayStat `json:"daily"`
+}
+
+// TGPostActivity — сколько комментариев сотрудник оставил под постом
+type TGPostActivity struct {
+	PostID   int `json:"post_id"`
+	Comments int `json:"comments"`
+}
+
+type TGActivityStats struct {
+	Posts    int `json:"posts"` // постов, под которыми есть комментарий сотрудника
+	Comments int `json:"comments"`
+}
+
+type TGEmployeeActivity struct {
+	Employee tg.Employee      `json:"employee"`
+	Activity []TGPostActivity `json:"activity"`
+	Stats    TGActivityStats  `json:"stats"`
+}
+
+type TGEmployeeActivityReport struct {
+	Posts     []PostRef            `json:"posts"`
+	Employees []TGEmployeeActivity `json:"employees"`
+}
+
+// TGPostLink — ссылка на пост канала, см. post.TGLink
+func TGPostLink(channel string, messageID int) string {
+	return post.TGLink(channel, messageID)
+}
+
+func NewTGPostStat(channel string, p tg.Post) TGPostStat {
+	return tgPostStat(post.FromTG(channel, p))
+}
+
+// tgPostStat — общий пост в терминах Telegram: Shares — пересылки
+func tgPostStat(p post.Post) TGPostStat {
+	return TGPostStat{
+		ID:        p.ID,
+		Date:      p.Date,
+		Link:      p.Link,
+		Text:      p.Text,
+		Views:     p.Metrics.Views,
+		Reactions: p.Metrics.Reactions,
+		Forwards:  p.Metrics.Shares,
+
+		Media:         p.Media,
+		ForwardedFrom: p.ForwardedFrom,
+	}
+}
+
+func tgTotals(m post.Metrics) TGTotals {
+	return TGTotals{Views: m.Views, Reactions: m.Reactions, Forwards: m.Shares}
+}
+
+func tgPostStats(a Analysis) TGPostStats {
+	stats := TGPostStats{Count: a.Count, Posts: []TGPostStat{}, Totals: tgTotals(a.Totals)}
+	for _, p := range a.Posts {
+		stats.Posts = append(stats.Posts, tgPostStat(p))
+	}
+	return stats
+}
+
+func (p TGPostStat) ShortText() string {
+	return preview(p.Text)
+}
+
+func BuildTGPostStats(channel string, posts []tg.Post) TGPostStats {
+	return tgPostStats(Analyze(post.FromTGPosts(channel, posts)))
+}
+
+// BuildTGRangeReport — AnalyzeRange в терминах Telegram
+func BuildTGRangeReport(channel string, from, to time.Time, posts []tg.Post) TGRangeReport {
+	a := AnalyzeRange(from, to, post.FromTGPosts(channel, posts))
+	report := TGRangeReport{
+		TGPostStats: tgPostStats(a.Analysis),
+		From:        from,
+		To:          to,
+		Averages:    tgTotals(a.Averages),
+		Daily:       []TGDayStat{},
+	}
+	for _, d := range a.Daily {
+		report.Daily = append(report.Daily, TGDayStat{Date: d.Date, Posts: d.Posts, Totals: tgTotals(d.Totals)})
+	}
+	return report
+}
+
+func (r TGRangeReport) Period() string {
+	return fmt.Sprintf("%s – %s", r.From.Format("02.01.2006"), r.To.Format("02.01.2006"))
+}
+
+// BuildTGEmployeeActivity считает комментарии сотрудников под постами канала.
+// Реакции в каналах анонимны, поэтому комментарии — единственная видимая активность.
+// Имя сотрудника берётся из его комментариев
+func BuildTGEmployeeActivity(channel string, posts []tg.Post, employees []tg.Employee, comments map[int][]tg.Comment) TGEmployeeActivityReport {
+	report := TGEmployeeActivityReport{Posts: []PostRef{}, Employees: []TGEmployeeActivity{}}
+
+	for _, p := range posts {
+		report.Posts = append(report.Posts, PostRef{
+			ID:   p.MessageID,
+			Date: time.Unix(int64(p.Date), 0),
+			Link: TGPostLink(channel, p.MessageID),
+			Text: p.Text,
+		})
+	}
+
+	for _, emp := range employees {
+		item := TGEmployeeActivity{Employee: emp, Activity: []TGPostActivity{}}
+		for _, p := range posts {
+			a := TGPostActivity{PostID: p.MessageID}
+			for _, c := range comments[p.MessageID] {
+				if !emp.Matches(c) {
+					continue
+				}
+				a.Comments++
+				if item.Employee.Name == "" {
+					item.Employee.Name = c.Name
+				}
+			}
+			if a.Comments > 0 {
+				item.Stats.Posts++
+				item.Stats.Comments += a.Comments
+			}
+			item.Activity = append(item.Activity, a)
+		}
+		if item.Employee.Name == "" {
+			item.Employee.Name = emp.Handle()
+		}
+		report.Employees = append(report.Employees, item)
+	}
+
+	sort.SliceStable(report.Employees, func(i, j int) bool {
+		a, b := report.Employees[i], report.Employees[j]
+		if a.Stats.Comments != b.Stats.Comments {
+			return a.Stats.Comments > b.Stats.Comments
+		}
+		return a.Employee.Name < b.Employee.Name
+	})
+	return report
+}
+
+// Symbol — значок ячейки в матрице активности
+func (a TGPostActivity) Symbol() string {
+	switch {
+	case a.Comments > 1:
+		return fmt.Sprintf("💬%d", a.Comments)
+	case a.Comments == 1:
+		return "💬"
+	}
+	return "➖"
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"smm-helper/tg"
+)
+
+const testChannel = "@kait_20_official"
+
+func tgPost(id int, date time.Time, views, reactions, forwards int) tg.Post {
+	p := tg.Post{MessageID: id, Date: int(date.Unix()), Text: "пост", Views: views, Forwards: forwards}
+	p.Reactions.TotalCount = reactions
+	return p
+}
+
+func TestBuildTGRangeReport(t *testing.T) {
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 3, 6, 23, 59, 59, 0, time.Local)
+	posts := []tg.Post{
+		tgPost(1, from.Add(10*time.Hour), 300, 9, 3),
+		tgPost(2, from.Add(12*time.Hour), 100, 3, 0),
+		tgPost(3, from.AddDate(0, 0, 2).Add(9*time.Hour), 50, 0, 1),
+	}
+
+	r := BuildTGRangeReport(testChannel, from, to, posts)
+	if r.Count != 3 || r.Totals != (TGTotals{Views: 450, Reactions: 12, Forwards: 4}) {
+		t.Errorf("Count = %d, Totals = %+v", r.Count, r.Totals)
+	}
+	if r.Averages != (TGTotals{Views: 150, Reactions: 4, Forwards: 1}) {
+		t.Errorf("Averages = %+v", r.Averages)
+	}
+	daily := []int{2, 0, 1}
+	if len(r.Daily) != len(daily) {
+		t.Fatalf("дней %d, ожидалось %d", len(r.Daily), len(daily))
+	}
+	for i, d := range r.Daily {
+		if d.Posts != daily[i] {
+			t.Errorf("Daily[%d].Posts = %d, ожидалось %d", i, d.Posts, daily[i])
+		}
+	}
+	if r.Daily[0].Totals.Views != 400 {
+		t.Errorf("просмотры первого дня %d, ожидалось 400", r.Daily[0].Totals.Views)
+	}
+	if want := "https://t.me/kait_20_official/1"; r.Posts[0].Link != want {
+		t.Errorf("Link = %q, ожидалось %q", r.Posts[0].Link, want)
+	}
+
+	empty := BuildTGPostStats(testChannel, nil)
+	if empty.Posts == nil || empty.Count != 0 {
+		t.Errorf("без постов: %+v, в JSON должен быть пустой массив", empty)
+	}
+}
+
+func TestBuildTGEmployeeActivity(t *testing.T) {
+	day := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	posts := []tg.Post{tgPost(1, day, 0, 0, 0), tgPost(2, day, 0, 0, 0)}
+	employees := []tg.Employee{
+		{ID: 10},                           // имя берётся из комментария
+		{Username: "boris", Name: "Борис"}, // сопоставление по username без учёта регистра
+		{Username: "vera"},                 // не комментировала — имя из @username
+		{ID: 12, Name: "Глеб"},
+	}
+	comments := map[int][]tg.Comment{
+		1: {
+			{UserID: 10, Name: "Анна"},
+			{UserID: 10, Name: "Анна"},
+			{UserID: 11, Username: "Boris", Name: "Боря"},
+			{UserID: 99, Username: "stranger", Name: "Гость"},
+		},
+		2: {
+			{UserID: 10, Name: "Анна"},
+			{UserID: 12, Name: "Глеб"},
+		},
+	}
+
+	r := BuildTGEmployeeActivity(testChannel, posts, employees, comments)
+
+	// По убыванию комментариев, при равенстве — по имени
+	want := []struct {
+		name     string
+		stats    TGActivityStats
+		activity []int
+	}{
+		{"Анна", TGActivityStats{Posts: 2, Comments: 3}, []int{2, 1}},
+		{"Борис", TGActivityStats{Posts: 1, Comments: 1}, []int{1, 0}},
+		{"Глеб", TGActivityStats{Posts: 1, Comments: 1}, []int{0, 1}},
+		{"@vera", TGActivityStats{}, []int{0, 0}},
+	}
+	if len(r.Employees) != len(want) {
+		t.Fatalf("сотрудников %d, ожидалось %d", len(r.Employees), len(want))
+	}
+	for i, w := range want {
+		e := r.Employees[i]
+		if e.Employee.Name != w.name || e.Stats != w.stats {
+			t.Errorf("Employees[%d] = %s %+v, ожидалось %s %+v", i, e.Employee.Name, e.Stats, w.name, w.stats)
+		}
+		for j, n := range w.activity {
+			if e.Activity[j].Comments != n {
+				t.Errorf("%s, пост %d: %d комментариев, ожидалось %d", w.name, j+1, e.Activity[j].Comments, n)
+			}
+		}
+	}
+	if s := r.Employees[0].Activity[0].Symbol(); s != "💬2" {
+		t.Errorf("Symbol() = %q", s)
+	}
+	if len(r.Posts) != 2 || r.Posts[1].Link != "https://t.me/kait_20_official/2" {
+		t.Errorf("Posts = %+v", r.Posts)
+	}
+}
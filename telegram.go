@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"smm-helper/tg"
+	"smm-helper/tg/mtproto"
+)
+
+// ========== СБОР ПОСТОВ TELEGRAM ==========
+
+func initTelegram() {
+	tc := cfg.Telegram
+
+	store, err := tg.NewPostStore(tc.PostsFile)
+	if err != nil {
+		log.Fatal("Ошибка загрузки постов Telegram: ", err)
+	}
+
+	tgClient = tg.NewClient(tc.BotToken, tc.ChannelID)
+	tgClient.Store = store
+	tgClient.DiscussionID = tc.DiscussionID
+	if tc.APIURL != "" {
+		tgClient.BaseURL = tc.APIURL
+	}
+
+	tgEmployees, err = tgClient.GetEmployees(tc.Employees)
+	if err != nil {
+		log.Fatal("Ошибка в списке сотрудников Telegram: ", err)
+	}
+
+	// Публичная страница канала: один клиент на всё приложение, чтобы работали пауза и кэш
+	tgPublic = tg.NewSimpleClient(tc.ChannelID)
+	if tc.Scrape.DelayMS > 0 {
+		tgPublic.Delay = time.Duration(tc.Scrape.DelayMS) * time.Millisecond
+	}
+	if tc.Scrape.MaxPages > 0 {
+		tgPublic.MaxPages = tc.Scrape.MaxPages
+	}
+	if tc.Scrape.CacheMinutes > 0 {
+		tgPublic.CacheTTL = time.Duration(tc.Scrape.CacheMinutes) * time.Minute
+	}
+
+	// MTProto — только если задано приложение. Сессию создаёт cmd/tglogin
+	if tc.MTProto.AppID != 0 {
+		tgMTProto = mtproto.NewClient(tc.MTProto.AppID, tc.MTProto.AppHash, tc.MTProto.SessionFile, tc.ChannelID)
+	}
+
+	if tc.Mode != "" && tc.Mode != "polling" && tc.Mode != "webhook" {
+		log.Fatalf("Неизвестный режим Telegram: %q (polling или webhook)", tc.Mode)
+	}
+	if tc.Mode == "webhook" && tc.WebhookSecret == "" {
+		log.Fatal("Для вебхука Telegram задайте telegram.webhook_secret")
+	}
+}
+
+// startTelegram запускает сбор постов канала выбранным способом
+func startTelegram() {
+	tc := cfg.Telegram
+	if tc.BotToken == "" || tc.Mode == "" {
+		return
+	}
+
+	switch tc.Mode {
+	case "polling":
+		fmt.Printf("✈️  Telegram: getUpdates для %s, сохранено постов %d\n", tc.ChannelID, tgClient.Store.Len())
+		go tgClient.Poll(context.Background())
+	case "webhook":
+		if tc.WebhookURL != "" {
+			if err := tgClient.SetWebhook(tc.WebhookURL, tc.WebhookSecret); err != nil {
+				fmt.Println("❌ Telegram setWebhook:", err)
+				return
+			}
+		}
+		fmt.Printf("✈️  Telegram: вебхук %s для %s\n", tc.WebhookURL, tc.ChannelID)
+	}
+}
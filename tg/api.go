@@ -1,21 +1,31 @@
 package tg
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 )
 
-const apiURL = "https://api.telegram.org/bot"
+const apiURL = "https://api.telegram.org"
 
 type Client struct {
-	BotToken  string
+	BotToken string
+	// ChannelID — @username или числовой ID канала
 	ChannelID string
+	// BaseURL можно подменить на тестовый сервер
+	BaseURL string
+	// DiscussionID — группа обсуждения канала, откуда берутся комментарии
+	DiscussionID string
+	// Store — куда сохраняются посты из обновлений
+	Store      *PostStore
+	httpClient *http.Client
 }
 
 type Channel struct {
@@ -24,22 +34,33 @@ type Channel struct {
 }
 
 type Employee struct {
-	ID       int64
-	Username string
-	Name     string
+	ID       int64  `json:"id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Name     string `json:"name"`
 }
 
 type Post struct {
 	MessageID int       `json:"message_id"`
 	Date      int       `json:"date"`
+	EditDate  int       `json:"edit_date,omitempty"`
 	Text      string    `json:"text"`
 	Views     int       `json:"views"`
 	Forwards  int       `json:"forwards"`
 	Reactions Reactions `json:"reactions"`
+	// Есть только у постов с публичной страницы t.me/s
+	Media         string `json:"media,omitempty"` // photo, video, album, document, poll...
+	ForwardedFrom string `json:"forwarded_from,omitempty"`
 }
 
 type Reactions struct {
-	TotalCount int `json:"total_count"`
+	TotalCount int             `json:"total_count"`
+	Items      []ReactionCount `json:"items,omitempty"`
+}
+
+// ReactionCount — число реакций одним эмодзи. Для своих эмодзи канала — их ID
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
 }
 
 type ActivityStats struct {
@@ -52,22 +73,42 @@ func NewClient(botToken, channelID string) *Client {
 	return &Client{
 		BotToken:  botToken,
 		ChannelID: channelID,
+		BaseURL:   apiURL,
+		// Таймаут больше, чем ожидание long polling в getUpdates
+		httpClient: &http.Client{Timeout: time.Duration(pollTimeout+15) * time.Second},
 	}
 }
 
 func (c *Client) makeRequest(method string, params url.Values) ([]byte, error) {
-	fullURL := apiURL + c.BotToken + "/" + method + "?" + params.Encode()
+	return c.makeRequestContext(context.Background(), method, params)
+}
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(fullURL)
+func (c *Client) makeRequestContext(ctx context.Context, method string, params url.Values) ([]byte, error) {
+	fullURL := strings.TrimRight(c.BaseURL, "/") + "/bot" + c.BotToken + "/" + method + "?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, withoutURL(err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", method, withoutURL(err))
 	}
 	defer resp.Body.Close()
 
 	return io.ReadAll(resp.Body)
 }
 
+// withoutURL убирает адрес запроса из ошибки сети: в нём токен бота, а ошибки пишутся
+// в лог и в файлы с состоянием
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
 func (c *Client) GetChannel() (*Channel, error) {
 	params := url.Values{}
 	params.Set("chat_id", c.ChannelID)
@@ -90,68 +131,70 @@ func (c *Client) GetChannel() (*Channel, error) {
 	return &result.Result, nil
 }
 
-func (c *Client) GetEmployees(usernames []string) (map[int64]Employee, error) {
-	employees := make(map[int64]Employee)
-
-	// Для Telegram нужно вручную задать ID пользователей
-	// или получить их через упоминания в постах
-	for i, username := range usernames {
-		employees[int64(i+1)] = Employee{
-			ID:       int64(i + 1),
-			Username: username,
-			Name:     username,
+// GetEmployees разбирает список сотрудников из настроек: @username или числовой ID.
+// Bot API не ищет пользователей по username, поэтому ID известен только если задан явно
+func (c *Client) GetEmployees(list []string) ([]Employee, error) {
+	employees := []Employee{}
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if id, err := strconv.ParseInt(item, 10, 64); err == nil {
+			employees = append(employees, Employee{ID: id})
+			continue
 		}
+		username := strings.TrimPrefix(item, "@")
+		if username == "" {
+			return nil, fmt.Errorf("пустой username сотрудника")
+		}
+		employees = append(employees, Employee{Username: username})
 	}
-
 	return employees, nil
 }
 
-func (c *Client) GetChannelPosts(limit int) ([]Post, error) {
-	// Telegram API не предоставляет прямого метода для получения истории постов канала
-	// Используем костыль: получаем последние сообщения через updates
-	// В реальном приложении нужно использовать MTProto или сохранять посты в БД
-
-	posts := []Post{}
-
-	// Получаем последние обновления (последние 100 сообщений)
-	params := url.Values{}
-	params.Set("chat_id", c.ChannelID)
-	params.Set("limit", strconv.Itoa(limit))
-
-	// ВАЖНО: Telegram Bot API не дает историю канала напрямую
-	// Нужно использовать либо:
-	// 1. Telethon/Pyrogram (Python)
-	// 2. TDLib
-	// 3. Сохранять посты в БД при получении через webhook
-
-	// Для демонстрации вернем пустой массив
-	// В production используй одно из решений выше
-
-	return posts, nil
+// Handle — @username или ID, если username не задан
+func (e Employee) Handle() string {
+	if e.Username != "" {
+		return "@" + e.Username
+	}
+	return strconv.FormatInt(e.ID, 10)
 }
 
-// Альтернативный метод: получение конкретного поста
-func (c *Client) GetPost(messageID int) (*Post, error) {
-	params := url.Values{}
-	params.Set("chat_id", c.ChannelID)
-	params.Set("message_id", strconv.Itoa(messageID))
+// URL — профиль в Telegram, если известен username
+func (e Employee) URL() string {
+	if e.Username == "" {
+		return ""
+	}
+	return "https://t.me/" + e.Username
+}
 
-	body, err := c.makeRequest("getUpdates", params)
-	if err != nil {
-		return nil, err
+// Matches проверяет, что комментарий оставил этот сотрудник
+func (e Employee) Matches(c Comment) bool {
+	if e.ID != 0 {
+		return e.ID == c.UserID
 	}
+	return c.Username != "" && strings.EqualFold(e.Username, c.Username)
+}
 
-	var result struct {
-		OK     bool `json:"ok"`
-		Result Post `json:"result"`
+// GetChannelPosts — последние посты канала, сохранённые из обновлений Bot API, новые первыми
+func (c *Client) GetChannelPosts(limit int) ([]Post, error) {
+	if c.Store == nil {
+		return nil, fmt.Errorf("хранилище постов Telegram не настроено")
 	}
-	json.Unmarshal(body, &result)
+	return c.Store.Latest(limit), nil
+}
 
-	if !result.OK {
+// GetPost возвращает сохранённый пост. Bot API не умеет читать сообщения канала по ID
+func (c *Client) GetPost(messageID int) (*Post, error) {
+	if c.Store == nil {
+		return nil, fmt.Errorf("хранилище постов Telegram не настроено")
+	}
+	post, found := c.Store.Get(messageID)
+	if !found {
 		return nil, fmt.Errorf("пост не найден")
 	}
-
-	return &result.Result, nil
+	return &post, nil
 }
 
 // Получение реакций (доступно только для супергрупп/каналов с включенными реакциями)
@@ -161,29 +204,13 @@ func (c *Client) GetReactions(messageID int) ([]int64, error) {
 	return []int64{}, nil
 }
 
-// Параллельное получение данных
-func (c *Client) GetPostsDataParallel(messageIDs []int) (map[int]*Post, error) {
+// GetPostsData — сохранённые посты по ID. Ненайденных постов в ответе нет
+func (c *Client) GetPostsData(messageIDs []int) (map[int]*Post, error) {
 	postsMap := make(map[int]*Post)
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	semaphore := make(chan struct{}, 5)
-
-	for _, msgID := range messageIDs {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			post, _ := c.GetPost(id)
-
-			mu.Lock()
+	for _, id := range messageIDs {
+		if post, err := c.GetPost(id); err == nil {
 			postsMap[id] = post
-			mu.Unlock()
-		}(msgID)
+		}
 	}
-
-	wg.Wait()
 	return postsMap, nil
 }
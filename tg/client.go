@@ -1,76 +0,0 @@
-package tg
-
-import (
-	"context"
-
-	"github.com/gotd/td/telegram"
-	"github.com/gotd/td/tg"
-)
-
-type TelegramClient struct {
-	client    *telegram.Client
-	api       *tg.Client
-	channelID int64
-	employees map[int64]Employee
-}
-
-type TGEmployee struct {
-	ID        int64
-	Username  string
-	FirstName string
-	LastName  string
-}
-
-type TGPost struct {
-	ID        int
-	Date      int
-	Text      string
-	Views     int
-	Forwards  int
-	Reactions int
-}
-
-type TGActivityStats struct {
-	Reactions int
-	Forwards  int
-	Total     int
-}
-
-func NewTelegramClient(apiID int, apiHash, phone string, channelUsername string) (*TelegramClient, error) {
-	// Создаем клиент
-	client := telegram.NewClient(apiID, apiHash, telegram.Options{})
-
-	ctx := context.Background()
-
-	// Авторизация
-	err := client.Run(ctx, func(ctx context.Context) error {
-		// Код авторизации
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &TelegramClient{
-		client:    client,
-		employees: make(map[int64]TGEmployee),
-	}, nil
-}
-
-func (tc *TelegramClient) GetChannelPosts(limit int) ([]TGPost, error) {
-	ctx := context.Background()
-	posts := []TGPost{}
-
-	err := tc.client.Run(ctx, func(ctx context.Context) error {
-		// Получаем историю канала
-		// Код для получения постов
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return posts, nil
-}
@@ -0,0 +1,265 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"smm-helper/tg"
+
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth"
+	tdapi "github.com/gotd/td/tg"
+)
+
+// Сколько сообщений просим за один messages.getHistory (максимум Telegram — 100)
+const historyPage = 100
+
+// Client читает историю канала от имени пользователя (MTProto). В отличие от Bot API
+// и t.me/s здесь есть вся история, просмотры, пересылки и реакции по каждому эмодзи.
+// Сессия хранится в файле; войти нужно один раз через cmd/tglogin
+type Client struct {
+	AppID       int
+	AppHash     string
+	SessionFile string
+	// Channel — @username канала
+	Channel string
+	// Delay — пауза между страницами истории, чтобы не получить FLOOD_WAIT
+	Delay time.Duration
+
+	// Один файл сессии нельзя использовать из двух подключений одновременно
+	mu sync.Mutex
+}
+
+func NewClient(appID int, appHash, sessionFile, channel string) *Client {
+	return &Client{
+		AppID:       appID,
+		AppHash:     appHash,
+		SessionFile: sessionFile,
+		Channel:     channel,
+		Delay:       500 * time.Millisecond,
+	}
+}
+
+func (c *Client) telegram() *telegram.Client {
+	return telegram.NewClient(c.AppID, c.AppHash, telegram.Options{
+		SessionStorage: &session.FileStorage{Path: c.SessionFile},
+		NoUpdates:      true,
+	})
+}
+
+// Run подключается, проверяет авторизацию и выполняет f
+func (c *Client) Run(ctx context.Context, f func(ctx context.Context, api *tdapi.Client) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client := c.telegram()
+	return client.Run(ctx, func(ctx context.Context) error {
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return fmt.Errorf("mtproto: проверка авторизации: %v", err)
+		}
+		if !status.Authorized {
+			return fmt.Errorf("mtproto: сессия %s не авторизована — выполните go run ./cmd/tglogin", c.SessionFile)
+		}
+		return f(ctx, client.API())
+	})
+}
+
+// Login проходит вход по номеру телефона: код из Telegram и, если включён, пароль 2FA.
+// Вопросы задаёт authenticator (см. cmd/tglogin). Возвращает имя вошедшего пользователя
+func (c *Client) Login(ctx context.Context, authenticator auth.UserAuthenticator) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := ""
+	client := c.telegram()
+	err := client.Run(ctx, func(ctx context.Context) error {
+		flow := auth.NewFlow(authenticator, auth.SendCodeOptions{})
+		if err := client.Auth().IfNecessary(ctx, flow); err != nil {
+			return err
+		}
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return err
+		}
+		if status.User != nil {
+			name = strings.TrimSpace(status.User.FirstName + " " + status.User.LastName)
+		}
+		return nil
+	})
+	return name, err
+}
+
+// GetPosts листает историю канала от новых постов к старым, пока не наберёт limit постов
+// или не дойдёт до постов старше since. Нулевые limit и since — без ограничения.
+// Альбом в истории — несколько сообщений, в ответе он один пост, как на t.me/s
+func (c *Client) GetPosts(ctx context.Context, limit int, since time.Time) ([]tg.Post, error) {
+	posts := []tg.Post{}
+
+	err := c.Run(ctx, func(ctx context.Context, api *tdapi.Client) error {
+		peer, err := c.resolve(ctx, api)
+		if err != nil {
+			return err
+		}
+
+		albums := map[int64]int{} // grouped_id → индекс поста в posts
+		offsetID := 0
+		for {
+			result, err := api.MessagesGetHistory(ctx, &tdapi.MessagesGetHistoryRequest{
+				Peer:     peer,
+				OffsetID: offsetID,
+				Limit:    historyPage,
+			})
+			if err != nil {
+				return fmt.Errorf("mtproto: messages.getHistory: %v", err)
+			}
+			messages, ok := result.AsModified()
+			if !ok || len(messages.GetMessages()) == 0 {
+				return nil
+			}
+
+			for _, m := range messages.GetMessages() {
+				msg, ok := m.(*tdapi.Message)
+				if !ok {
+					// Служебные сообщения (закреп, смена названия) — не посты
+					offsetID = m.GetID()
+					continue
+				}
+				offsetID = msg.ID
+
+				if !since.IsZero() && time.Unix(int64(msg.Date), 0).Before(since) {
+					return nil
+				}
+
+				post := ConvertMessage(msg)
+				if groupID, ok := msg.GetGroupedID(); ok {
+					if i, found := albums[groupID]; found {
+						// Ссылка на альбом ведёт на первое сообщение, текст — у одного из них
+						posts[i].MessageID = post.MessageID
+						if posts[i].Text == "" {
+							posts[i].Text = post.Text
+						}
+						continue
+					}
+					post.Media = "album"
+					albums[groupID] = len(posts)
+				}
+
+				if limit > 0 && len(posts) >= limit {
+					return nil
+				}
+				posts = append(posts, post)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.Delay):
+			}
+		}
+	})
+	return posts, err
+}
+
+// resolve находит канал по @username
+func (c *Client) resolve(ctx context.Context, api *tdapi.Client) (tdapi.InputPeerClass, error) {
+	username := strings.TrimPrefix(c.Channel, "@")
+	if _, err := strconv.ParseInt(username, 10, 64); err == nil || username == "" {
+		return nil, fmt.Errorf("mtproto: нужен @username канала, а не %q", c.Channel)
+	}
+
+	resolved, err := api.ContactsResolveUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("mtproto: канал %s не найден: %v", c.Channel, err)
+	}
+	for _, chat := range resolved.Chats {
+		if channel, ok := chat.(*tdapi.Channel); ok {
+			return channel.AsInputPeer(), nil
+		}
+	}
+	return nil, fmt.Errorf("mtproto: %s — не канал", c.Channel)
+}
+
+// ConvertMessage переводит сообщение MTProto в пост в формате остального приложения
+func ConvertMessage(msg *tdapi.Message) tg.Post {
+	post := tg.Post{
+		MessageID: msg.ID,
+		Date:      msg.Date,
+		Text:      msg.Message,
+	}
+	post.EditDate, _ = msg.GetEditDate()
+	post.Views, _ = msg.GetViews()
+	post.Forwards, _ = msg.GetForwards()
+
+	if fwd, ok := msg.GetFwdFrom(); ok {
+		post.ForwardedFrom = fwd.FromName
+		if post.ForwardedFrom == "" {
+			post.ForwardedFrom = "канал"
+		}
+	}
+	if media, ok := msg.GetMedia(); ok {
+		post.Media = mediaType(media)
+	}
+	if reactions, ok := msg.GetReactions(); ok {
+		for _, r := range reactions.Results {
+			post.Reactions.TotalCount += r.Count
+			post.Reactions.Items = append(post.Reactions.Items, tg.ReactionCount{
+				Emoji: reactionEmoji(r.Reaction),
+				Count: r.Count,
+			})
+		}
+	}
+	return post
+}
+
+// reactionEmoji — эмодзи реакции. Для своих эмодзи канала — их ID, как на t.me/s
+func reactionEmoji(r tdapi.ReactionClass) string {
+	switch r := r.(type) {
+	case *tdapi.ReactionEmoji:
+		return r.Emoticon
+	case *tdapi.ReactionCustomEmoji:
+		return strconv.FormatInt(r.DocumentID, 10)
+	}
+	return "?"
+}
+
+// mediaType — тип вложения в тех же обозначениях, что у парсера t.me/s
+func mediaType(media tdapi.MessageMediaClass) string {
+	switch m := media.(type) {
+	case *tdapi.MessageMediaPhoto:
+		return "photo"
+	case *tdapi.MessageMediaPoll:
+		return "poll"
+	case *tdapi.MessageMediaGeo, *tdapi.MessageMediaVenue, *tdapi.MessageMediaGeoLive:
+		return "location"
+	case *tdapi.MessageMediaWebPage:
+		return "link"
+	case *tdapi.MessageMediaDocument:
+		doc, ok := m.Document.(*tdapi.Document)
+		if !ok {
+			return "document"
+		}
+		for _, attr := range doc.Attributes {
+			switch a := attr.(type) {
+			case *tdapi.DocumentAttributeVideo:
+				if a.RoundMessage {
+					return "round"
+				}
+				return "video"
+			case *tdapi.DocumentAttributeAudio:
+				if a.Voice {
+					return "voice"
+				}
+			case *tdapi.DocumentAttributeSticker:
+				return "sticker"
+			}
+		}
+		return "document"
+	}
+	return ""
+}
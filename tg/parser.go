@@ -3,73 +3,360 @@ package tg
 import (
 	"fmt"
 	"io"
+	"math"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/net/html"
 )
 
+const publicURL = "https://t.me/s/"
+
+// SimpleClient читает публичную страницу канала t.me/s/<канал>. Страница отдаёт ~20
+// последних постов, более старые листаются курсором ?before=<id>
 type SimpleClient struct {
 	ChannelUsername string
+	// BaseURL можно подменить на тестовый сервер
+	BaseURL string
+	// Delay — пауза между запросами, чтобы t.me не начал отвечать 429
+	Delay time.Duration
+	// MaxPages ограничивает глубину листания за один вызов
+	MaxPages int
+	// CacheTTL — сколько хранить разобранные страницы
+	CacheTTL time.Duration
+
+	httpClient  *http.Client
+	mu          sync.Mutex
+	lastRequest time.Time
+	pages       map[int]cachedPage
+}
+
+type cachedPage struct {
+	posts   []Post
+	fetched time.Time
 }
 
 func NewSimpleClient(username string) *SimpleClient {
-	return &SimpleClient{ChannelUsername: username}
+	return &SimpleClient{
+		ChannelUsername: strings.TrimPrefix(username, "@"),
+		BaseURL:         publicURL,
+		Delay:           time.Second,
+		MaxPages:        50,
+		CacheTTL:        10 * time.Minute,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		pages:           map[int]cachedPage{},
+	}
+}
+
+// Парсинг через t.me (публичный просмотр). Посты возвращаются новые первыми
+func (sc *SimpleClient) GetRecentPosts(limit int) ([]Post, error) {
+	return sc.GetPosts(limit, time.Time{})
+}
+
+// GetPosts листает канал от новых постов к старым, пока не наберёт limit постов
+// или не дойдёт до постов старше since. Нулевые limit и since — без ограничения,
+// но не глубже MaxPages страниц. Посты возвращаются новые первыми
+func (sc *SimpleClient) GetPosts(limit int, since time.Time) ([]Post, error) {
+	result := []Post{}
+	before := 0
+
+	for page := 0; page < sc.MaxPages; page++ {
+		posts, err := sc.fetchPage(before)
+		if err != nil {
+			// Что успели собрать — отдаём, ошибку тоже
+			return result, err
+		}
+
+		// На странице посты идут от старых к новым
+		next := before
+		for i := len(posts) - 1; i >= 0; i-- {
+			p := posts[i]
+			if before > 0 && p.MessageID >= before {
+				continue
+			}
+			next = p.MessageID
+			if !since.IsZero() && p.Date > 0 && time.Unix(int64(p.Date), 0).Before(since) {
+				return result, nil
+			}
+			result = append(result, p)
+			if limit > 0 && len(result) >= limit {
+				return result, nil
+			}
+		}
+
+		// Курсор не сдвинулся — дошли до начала канала
+		if next == before || next <= 1 {
+			return result, nil
+		}
+		before = next
+	}
+	return result, nil
+}
+
+// ClearCache забывает загруженные страницы
+func (sc *SimpleClient) ClearCache() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.pages = map[int]cachedPage{}
 }
 
-// Парсинг через t.me (публичный просмотр)
-func (sc *SimpleClient) GetRecentPosts(limit int) ([]map[string]interface{}, error) {
-	url := fmt.Sprintf("https://t.me/s/%s", sc.ChannelUsername)
+// fetchPage загружает одну страницу. before=0 — самые свежие посты
+func (sc *SimpleClient) fetchPage(before int) ([]Post, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	if cached, ok := sc.pages[before]; ok && time.Since(cached.fetched) < sc.CacheTTL {
+		return cached.posts, nil
+	}
+
+	url := sc.BaseURL + sc.ChannelUsername
+	if before > 0 {
+		url += "?before=" + strconv.Itoa(before)
+	}
+
+	posts, err := sc.get(url)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	html := string(body)
+	sc.pages[before] = cachedPage{posts: posts, fetched: time.Now()}
+	return posts, nil
+}
+
+// get выполняет запрос с паузой после предыдущего. На 429 один раз ждёт Retry-After
+func (sc *SimpleClient) get(url string) ([]Post, error) {
+	for attempt := 0; ; attempt++ {
+		if wait := sc.Delay - time.Since(sc.lastRequest); wait > 0 {
+			time.Sleep(wait)
+		}
+		sc.lastRequest = time.Now()
+
+		resp, err := sc.httpClient.Get(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt == 0 {
+			resp.Body.Close()
+			retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			if retry <= 0 || retry > 60 {
+				retry = 5
+			}
+			time.Sleep(time.Duration(retry) * time.Second)
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("t.me: статус %d", resp.StatusCode)
+		}
+
+		posts, err := ParsePage(resp.Body)
+		resp.Body.Close()
+		return posts, err
+	}
+}
+
+// Типы вложений по классам блоков t.me/s
+var mediaClasses = []struct {
+	class string
+	media string
+}{
+	{"tgme_widget_message_grouped_wrap", "album"},
+	{"tgme_widget_message_photo_wrap", "photo"},
+	{"tgme_widget_message_video_player", "video"},
+	{"tgme_widget_message_roundvideo_player", "round"},
+	{"tgme_widget_message_voice_player", "voice"},
+	{"tgme_widget_message_document_wrap", "document"},
+	{"tgme_widget_message_poll", "poll"},
+	{"tgme_widget_message_sticker_wrap", "sticker"},
+	{"tgme_widget_message_location_wrap", "location"},
+	{"tgme_widget_message_link_preview", "link"},
+}
 
-	// Парсим HTML для извлечения постов
-	posts := []map[string]interface{}{}
+// ParsePage разбирает HTML страницы t.me/s/<канал> в посты в порядке страницы.
+// Каждый пост — блок div.tgme_widget_message с атрибутом data-post="<канал>/<id>"
+func ParsePage(r io.Reader) ([]Post, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("t.me: %v", err)
+	}
 
-	// Находим блоки постов
-	re := regexp.MustCompile(`<div class="tgme_widget_message_text[^>]*>(.*?)</div>`)
-	matches := re.FindAllStringSubmatch(html, -1)
+	posts := []Post{}
+	walk(doc, func(n *html.Node) bool {
+		if !hasClass(n, "tgme_widget_message") || attr(n, "data-post") == "" {
+			return true
+		}
+		if post, ok := parseMessage(n); ok {
+			posts = append(posts, post)
+		}
+		return false
+	})
+	return posts, nil
+}
 
-	viewsRe := regexp.MustCompile(`<span class="tgme_widget_message_views">([^<]+)</span>`)
-	viewsMatches := viewsRe.FindAllStringSubmatch(html, -1)
+func parseMessage(n *html.Node) (Post, bool) {
+	dataPost := attr(n, "data-post")
+	id, err := strconv.Atoi(dataPost[strings.LastIndex(dataPost, "/")+1:])
+	if err != nil {
+		return Post{}, false
+	}
+	post := Post{MessageID: id}
 
-	for i, match := range matches {
-		if i >= limit {
-			break
+	walk(n, func(c *html.Node) bool {
+		switch {
+		// Цитата из ответа содержит чужой текст и медиа — пропускаем целиком
+		case hasClass(c, "tgme_widget_message_reply"):
+			return false
+		case hasClass(c, "tgme_widget_message_forwarded_from"):
+			post.ForwardedFrom = forwardedName(c)
+			return false
+		case hasClass(c, "tgme_widget_message_text") && post.Text == "":
+			post.Text = strings.TrimSpace(textContent(c))
+			return false
+		case hasClass(c, "tgme_widget_message_views"):
+			post.Views = parseCount(textContent(c))
+			return false
+		case hasClass(c, "tgme_widget_message_reactions"):
+			post.Reactions = parseReactions(c)
+			return false
+		case c.Type == html.ElementNode && c.Data == "time" && post.Date == 0:
+			if t, err := time.Parse(time.RFC3339, attr(c, "datetime")); err == nil {
+				post.Date = int(t.Unix())
+			}
 		}
 
-		text := cleanHTML(match[1])
-		views := 0
+		if post.Media == "" {
+			for _, m := range mediaClasses {
+				if hasClass(c, m.class) {
+					post.Media = m.media
+					break
+				}
+			}
+		}
+		return true
+	})
+	return post, true
+}
+
+// forwardedName — имя источника пересылки без подписи «Forwarded from»
+func forwardedName(n *html.Node) string {
+	name := ""
+	walk(n, func(c *html.Node) bool {
+		if hasClass(c, "tgme_widget_message_forwarded_from_name") {
+			name = strings.TrimSpace(textContent(c))
+			return false
+		}
+		return true
+	})
+	if name == "" {
+		name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(textContent(n)), "Forwarded from"))
+	}
+	return name
+}
 
-		if i < len(viewsMatches) {
-			viewsStr := strings.ReplaceAll(viewsMatches[i][1], "K", "000")
-			viewsStr = strings.ReplaceAll(viewsStr, "M", "000000")
-			viewsStr = strings.TrimSpace(viewsStr)
-			views, _ = strconv.Atoi(viewsStr)
+// parseReactions разбирает span.tgme_reaction: эмодзи в <i class="emoji"><b>…</b></i>,
+// а число — оставшийся текст. Платные реакции — звёзды
+func parseReactions(n *html.Node) Reactions {
+	reactions := Reactions{}
+	walk(n, func(c *html.Node) bool {
+		if !hasClass(c, "tgme_reaction") {
+			return true
 		}
 
-		posts = append(posts, map[string]interface{}{
-			"id":    i + 1,
-			"text":  text,
-			"views": views,
-			"date":  time.Now().Unix(),
+		emoji := ""
+		var count strings.Builder
+		walk(c, func(e *html.Node) bool {
+			switch {
+			case hasClass(e, "emoji") || (e.Type == html.ElementNode && e.Data == "tg-emoji"):
+				emoji = strings.TrimSpace(textContent(e))
+				if emoji == "" {
+					emoji = attr(e, "emoji-id")
+				}
+				return false
+			case e.Type == html.TextNode:
+				count.WriteString(e.Data)
+			}
+			return true
 		})
+		if hasClass(c, "tgme_reaction_paid") {
+			emoji = "⭐"
+		}
+
+		rc := ReactionCount{Emoji: emoji, Count: parseCount(count.String())}
+		reactions.Items = append(reactions.Items, rc)
+		reactions.TotalCount += rc.Count
+		return false
+	})
+	return reactions
+}
+
+// walk обходит дерево в глубину. fn возвращает false, чтобы не заходить внутрь узла
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if fn(c) {
+			walk(c, fn)
+		}
 	}
+}
 
-	return posts, nil
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
 }
 
-func cleanHTML(s string) string {
-	// Убираем HTML теги
-	re := regexp.MustCompile(`<[^>]*>`)
-	return re.ReplaceAllString(s, "")
+// textContent — текст узла, <br> превращается в перенос строки
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			sb.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return sb.String()
+}
+
+// parseCount разбирает счётчики вида 980, 1.2K, 3M
+func parseCount(s string) int {
+	s = strings.TrimSpace(s)
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier, s = 1e3, strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		multiplier, s = 1e6, strings.TrimSuffix(s, "M")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	// 32.3 * 1e3 = 32299.999…, без округления счётчик теряет единицу
+	return int(math.Round(v * multiplier))
 }
@@ -0,0 +1,179 @@
+package tg
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test ./tg -run ParsePage -update перезаписывает эталоны testdata/*.golden.json
+var update = flag.Bool("update", false, "перезаписать эталонные файлы")
+
+// Синтетические страницы по разметке t.me/s, написанные вручную, и разобранные из них посты
+func TestParsePageGolden(t *testing.T) {
+	pages, err := filepath.Glob("testdata/*.html")
+	if err != nil || len(pages) == 0 {
+		t.Fatalf("нет страниц в testdata: %v", err)
+	}
+
+	for _, page := range pages {
+		t.Run(filepath.Base(page), func(t *testing.T) {
+			f, err := os.Open(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			posts, err := ParsePage(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := strings.TrimSuffix(page, ".html") + ".golden.json"
+			if *update {
+				data, _ := json.MarshalIndent(posts, "", "  ")
+				if err := os.WriteFile(golden, append(data, '\n'), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("нет эталона (запустите с -update): %v", err)
+			}
+			var want []Post
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+			if len(posts) != len(want) {
+				t.Fatalf("постов %d, в эталоне %d", len(posts), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(posts[i], want[i]) {
+					t.Errorf("пост %d:\n получено %+v\nожидалось %+v", want[i].MessageID, posts[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+// Явные проверки тех мест, где парсер уже ошибался. Эталон не даст их случайно «обновить»
+func TestParsePageEdgeCases(t *testing.T) {
+	f, err := os.Open("testdata/channel.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	posts, err := ParsePage(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[int]Post{}
+	for _, p := range posts {
+		byID[p.MessageID] = p
+	}
+
+	// Пост без текста не забирает текст соседнего поста
+	if p := byID[102]; p.Text != "" || p.Media != "photo" || p.Views != 980 {
+		t.Errorf("пост без текста: %+v", p)
+	}
+	if p := byID[103]; p.Text != "Напоминаем: регистрация до пятницы." || p.Media != "" {
+		t.Errorf("ответ взял текст или медиа из цитаты: %+v", p)
+	}
+	if p := byID[101]; p.Text != "День открытых дверей!\n\nЖдём всех в субботу в 11:00, актовый зал. #события" {
+		t.Errorf("текст с <br>: %q", p.Text)
+	}
+	if p := byID[101]; p.Date != int(time.Date(2024, 3, 4, 9, 0, 5, 0, time.UTC).Unix()) {
+		t.Errorf("дата: %d", p.Date)
+	}
+	if p := byID[104]; p.Date != int(time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC).Unix()) {
+		t.Errorf("дата с часовым поясом: %d", p.Date)
+	}
+
+	want := Reactions{TotalCount: 250 + 7 + 1100, Items: []ReactionCount{
+		{Emoji: "⭐", Count: 250},
+		{Emoji: "5368324170671202286", Count: 7},
+		{Emoji: "🔥", Count: 1100},
+	}}
+	if p := byID[104]; !reflect.DeepEqual(p.Reactions, want) {
+		t.Errorf("платные и свои реакции: %+v", p.Reactions)
+	}
+	if p := byID[104]; p.ForwardedFrom != "Минпросвещения России" || p.Views != 4100000 {
+		t.Errorf("пересланный пост: %+v", p)
+	}
+	if p := byID[105]; p.ForwardedFrom != "Иван Петров" || p.Media != "album" {
+		t.Errorf("пересланный альбом: %+v", p)
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"0", 0},
+		{"980", 980},
+		{" 15 ", 15},
+		{"1K", 1000},
+		{"1.2K", 1200},
+		{"32.3K", 32300},
+		{"3.05K", 3050},
+		{"4.1M", 4100000},
+		{"1.7M", 1700000},
+		{"views", 0},
+	}
+	for _, tt := range tests {
+		if got := parseCount(tt.in); got != tt.want {
+			t.Errorf("parseCount(%q) = %d, ожидалось %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// GetPosts листает страницы курсором before и останавливается на начале канала
+func TestGetPosts(t *testing.T) {
+	requests := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Query().Get("before") {
+		case "":
+			http.ServeFile(w, r, "testdata/channel.html")
+		case "101":
+			http.ServeFile(w, r, "testdata/channel_before.html")
+		default:
+			w.Write([]byte("<html><body></body></html>"))
+		}
+	}))
+	defer srv.Close()
+
+	sc := NewSimpleClient("@smm_college")
+	sc.BaseURL = srv.URL + "/s/"
+	sc.Delay = 0
+
+	posts, err := sc.GetPosts(0, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int{}
+	for _, p := range posts {
+		ids = append(ids, p.MessageID)
+	}
+	if want := []int{108, 107, 105, 104, 103, 102, 101, 100, 99, 98}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("посты %v, ожидалось %v", ids, want)
+	}
+	if want := []string{"/s/smm_college", "/s/smm_college?before=101", "/s/smm_college?before=98"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("запросы %v, ожидалось %v", requests, want)
+	}
+
+	// Повторный вызов берёт страницы из кэша, since обрывает листание
+	posts, _ = sc.GetPosts(0, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC))
+	if len(posts) != 5 || len(requests) != 3 {
+		t.Errorf("с since: постов %d, запросов %d", len(posts), len(requests))
+	}
+}
@@ -0,0 +1,179 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Ограничения Bot API: подпись к фото короче текста сообщения, в альбоме до 10 фото
+const (
+	MaxCaptionLength = 1024
+	MaxMessageLength = 4096
+	MaxAlbumSize     = 10
+)
+
+// InputFile — файл, который загружается в Telegram вместе с запросом
+type InputFile struct {
+	Name string
+	Data []byte
+}
+
+// SendMessage публикует текст с разметкой HTML и возвращает ID сообщения
+func (c *Client) SendMessage(ctx context.Context, chatID, html string) (int, error) {
+	fields := map[string]string{
+		"chat_id":    chatID,
+		"text":       html,
+		"parse_mode": "HTML",
+	}
+	var msg Message
+	if err := c.upload(ctx, "sendMessage", fields, nil, &msg); err != nil {
+		return 0, err
+	}
+	return msg.MessageID, nil
+}
+
+// SendText отправляет обычный текст без разметки и без превью ссылок — для служебных
+// сообщений: напоминаний, дайджестов, алертов
+func (c *Client) SendText(ctx context.Context, chatID, text string) (int, error) {
+	fields := map[string]string{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": "true",
+	}
+	var msg Message
+	if err := c.upload(ctx, "sendMessage", fields, nil, &msg); err != nil {
+		return 0, err
+	}
+	return msg.MessageID, nil
+}
+
+// SendDocument отправляет файл с подписью без разметки
+func (c *Client) SendDocument(ctx context.Context, chatID string, doc InputFile, caption string) (int, error) {
+	fields := map[string]string{"chat_id": chatID}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+	var msg Message
+	if err := c.upload(ctx, "sendDocument", fields, map[string]InputFile{"document": doc}, &msg); err != nil {
+		return 0, err
+	}
+	return msg.MessageID, nil
+}
+
+// SendPhotos загружает фото с подписью в HTML: одно — через sendPhoto, несколько — альбомом
+// через sendMediaGroup. Подпись показывается под альбомом. Возвращает ID сообщений
+func (c *Client) SendPhotos(ctx context.Context, chatID string, photos []InputFile, caption string) ([]int, error) {
+	switch {
+	case len(photos) == 0:
+		return nil, fmt.Errorf("нет фото для отправки")
+	case len(photos) > MaxAlbumSize:
+		return nil, fmt.Errorf("в альбоме не больше %d фото", MaxAlbumSize)
+	}
+
+	if len(photos) == 1 {
+		fields := map[string]string{"chat_id": chatID}
+		if caption != "" {
+			fields["caption"] = caption
+			fields["parse_mode"] = "HTML"
+		}
+		var msg Message
+		if err := c.upload(ctx, "sendPhoto", fields, map[string]InputFile{"photo": photos[0]}, &msg); err != nil {
+			return nil, err
+		}
+		return []int{msg.MessageID}, nil
+	}
+
+	type inputMedia struct {
+		Type      string `json:"type"`
+		Media     string `json:"media"`
+		Caption   string `json:"caption,omitempty"`
+		ParseMode string `json:"parse_mode,omitempty"`
+	}
+	media := []inputMedia{}
+	files := map[string]InputFile{}
+	for i, photo := range photos {
+		name := "photo" + strconv.Itoa(i)
+		item := inputMedia{Type: "photo", Media: "attach://" + name}
+		if i == 0 && caption != "" {
+			item.Caption = caption
+			item.ParseMode = "HTML"
+		}
+		media = append(media, item)
+		files[name] = photo
+	}
+	mediaJSON, _ := json.Marshal(media)
+
+	var messages []Message
+	err := c.upload(ctx, "sendMediaGroup", map[string]string{
+		"chat_id": chatID,
+		"media":   string(mediaJSON),
+	}, files, &messages)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int{}
+	for _, m := range messages {
+		ids = append(ids, m.MessageID)
+	}
+	return ids, nil
+}
+
+// upload вызывает метод Bot API запросом multipart/form-data и разбирает result в v
+func (c *Client) upload(ctx context.Context, method string, fields map[string]string, files map[string]InputFile, v interface{}) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for key, value := range fields {
+		mw.WriteField(key, value)
+	}
+	for key, file := range files {
+		part, err := mw.CreateFormFile(key, file.Name)
+		if err != nil {
+			return err
+		}
+		part.Write(file.Data)
+	}
+	mw.Close()
+
+	endpoint := strings.TrimRight(c.BaseURL, "/") + "/bot" + c.BotToken + "/" + method
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, &buf)
+	if err != nil {
+		return withoutURL(err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, withoutURL(err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("%s: неверный ответ: %v", method, err)
+	}
+	if !result.OK {
+		if result.Parameters.RetryAfter > 0 {
+			return fmt.Errorf("%s: %s (повтор через %d с)", method, result.Description, result.Parameters.RetryAfter)
+		}
+		return fmt.Errorf("%s: %s", method, result.Description)
+	}
+	return json.Unmarshal(result.Result, v)
+}
@@ -0,0 +1,99 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Ошибка сети не должна содержать адрес запроса: в нём токен бота,
+// а кросспостер записывает ошибки в data/crosspost.json
+func TestSendErrorHidesToken(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // соединение будет отклонено
+
+	c := NewClient("123456:SECRET-TOKEN", "@smm_channel")
+	c.BaseURL = srv.URL
+
+	_, errText := c.SendText(context.Background(), "1", "текст")
+	_, errPhotos := c.SendPhotos(context.Background(), "1", []InputFile{{Name: "a.jpg", Data: []byte("jpg")}}, "")
+	_, errUpdates := c.GetUpdates(context.Background(), 0, 0)
+	for _, err := range []error{errText, errPhotos, errUpdates} {
+		if err == nil {
+			t.Fatal("ожидалась ошибка соединения")
+		}
+		if strings.Contains(err.Error(), "SECRET-TOKEN") {
+			t.Errorf("токен в тексте ошибки: %v", err)
+		}
+	}
+}
+
+func TestSendPhotosAlbum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMediaGroup" {
+			t.Errorf("неожиданный метод %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		var media []struct {
+			Type, Media, Caption string
+		}
+		json.Unmarshal([]byte(r.FormValue("media")), &media)
+		if len(media) != 2 || media[0].Media != "attach://photo0" || media[0].Caption != "<b>Подпись</b>" || media[1].Caption != "" {
+			t.Errorf("media = %+v", media)
+		}
+		for i, want := range []string{"first", "second"} {
+			f, _, err := r.FormFile("photo" + strconv.Itoa(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(f)
+			if string(data) != want {
+				t.Errorf("photo%d = %q", i, data)
+			}
+		}
+		w.Write([]byte(`{"ok":true,"result":[{"message_id":7},{"message_id":8}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", "@smm_channel")
+	c.BaseURL = srv.URL
+	ids, err := c.SendPhotos(context.Background(), "@smm_channel", []InputFile{
+		{Name: "1.jpg", Data: []byte("first")},
+		{Name: "2.jpg", Data: []byte("second")},
+	}, "<b>Подпись</b>")
+	if err != nil || len(ids) != 2 || ids[0] != 7 || ids[1] != 8 {
+		t.Errorf("ids = %v, ошибка %v", ids, err)
+	}
+}
+
+func TestSendDocument(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendDocument" {
+			t.Errorf("неожиданный метод %s", r.URL.Path)
+		}
+		f, fh, err := r.FormFile("document")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(f)
+		if fh.Filename != "digest.csv" || string(data) != "a;b" || r.FormValue("caption") != "Дайджест" || r.FormValue("chat_id") != "42" {
+			t.Errorf("документ %q %q, подпись %q", fh.Filename, data, r.FormValue("caption"))
+		}
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", "")
+	c.BaseURL = srv.URL
+	_, err := c.SendDocument(context.Background(), "42", InputFile{Name: "digest.csv", Data: []byte("a;b")}, "Дайджест")
+	if err == nil || err.Error() != "sendDocument: Bad Request: chat not found" {
+		t.Errorf("ошибка %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package tg
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"smm-helper/storage"
+)
+
+// PostStore — посты канала, полученные из обновлений Bot API, в JSON файле.
+// Там же хранится offset getUpdates, чтобы после перезапуска не получать обновления повторно,
+// и комментарии из группы обсуждения
+type PostStore struct {
+	path string
+	data storeData
+	mu   sync.RWMutex
+}
+
+type storeData struct {
+	Offset int          `json:"offset"`
+	Posts  map[int]Post `json:"posts"`
+	// Threads: ID пересланного поста в группе обсуждения → ID поста канала
+	Threads  map[int]int       `json:"threads"`
+	Comments map[int][]Comment `json:"comments"`
+}
+
+// Comment — комментарий к посту канала в группе обсуждения
+type Comment struct {
+	MessageID int    `json:"message_id"`
+	Date      int    `json:"date"`
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username,omitempty"`
+	Name      string `json:"name"`
+}
+
+func NewPostStore(path string) (*PostStore, error) {
+	s := &PostStore{path: path}
+	if err := storage.ReadJSON(path, &s.data); err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+	}
+	if s.data.Posts == nil {
+		s.data.Posts = map[int]Post{}
+	}
+	if s.data.Threads == nil {
+		s.data.Threads = map[int]int{}
+	}
+	if s.data.Comments == nil {
+		s.data.Comments = map[int][]Comment{}
+	}
+	return s, nil
+}
+
+// Upsert сохраняет новый пост или применяет к нему правку. В edited_channel_post нет
+// просмотров и реакций — их сохранённые значения остаются, если правка их не принесла
+func (s *PostStore) Upsert(p Post) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, found := s.data.Posts[p.MessageID]; found {
+		if p.Views == 0 {
+			p.Views = old.Views
+		}
+		if p.Forwards == 0 {
+			p.Forwards = old.Forwards
+		}
+		if p.Reactions.TotalCount == 0 && len(p.Reactions.Items) == 0 {
+			p.Reactions = old.Reactions
+		}
+	}
+	s.data.Posts[p.MessageID] = p
+}
+
+func (s *PostStore) Get(messageID int) (Post, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, found := s.data.Posts[messageID]
+	return p, found
+}
+
+// Latest — последние limit постов, новые первыми
+func (s *PostStore) Latest(limit int) []Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]Post, 0, len(s.data.Posts))
+	for _, p := range s.data.Posts {
+		posts = append(posts, p)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].MessageID > posts[j].MessageID })
+	if limit > 0 && len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts
+}
+
+// SetReactions обновляет реакции сохранённого поста. Реакции к неизвестным постам не сохраняются
+func (s *PostStore) SetReactions(messageID int, reactions Reactions) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, found := s.data.Posts[messageID]
+	if !found {
+		return false
+	}
+	p.Reactions = reactions
+	s.data.Posts[messageID] = p
+	return true
+}
+
+// Range — посты за период [from, to], новые первыми
+func (s *PostStore) Range(from, to time.Time) []Post {
+	posts := []Post{}
+	for _, p := range s.Latest(0) {
+		date := time.Unix(int64(p.Date), 0)
+		if !date.Before(from) && !date.After(to) {
+			posts = append(posts, p)
+		}
+	}
+	return posts
+}
+
+// LinkThread запоминает, к какому посту канала относится ветка комментариев
+func (s *PostStore) LinkThread(threadID, postID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Threads[threadID] = postID
+}
+
+func (s *PostStore) ThreadPost(threadID int) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	postID, found := s.data.Threads[threadID]
+	return postID, found
+}
+
+// AddComment добавляет комментарий к посту. Повторно полученный комментарий не дублируется
+func (s *PostStore) AddComment(postID int, c Comment) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, old := range s.data.Comments[postID] {
+		if old.MessageID == c.MessageID {
+			return false
+		}
+	}
+	s.data.Comments[postID] = append(s.data.Comments[postID], c)
+	return true
+}
+
+// Comments — комментарии к указанным постам
+func (s *PostStore) Comments(postIDs []int) map[int][]Comment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(map[int][]Comment, len(postIDs))
+	for _, id := range postIDs {
+		if comments := s.data.Comments[id]; len(comments) > 0 {
+			result[id] = append([]Comment(nil), comments...)
+		}
+	}
+	return result
+}
+
+func (s *PostStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.data.Posts)
+}
+
+func (s *PostStore) Offset() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.Offset
+}
+
+func (s *PostStore) SetOffset(offset int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Offset = offset
+}
+
+func (s *PostStore) Save() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return storage.WriteJSON(s.path, s.data)
+}
@@ -0,0 +1,289 @@
+package tg
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Сколько секунд getUpdates ждёт новых обновлений
+const pollTimeout = 30
+
+// Первая пауза после ошибки getUpdates, дальше она удваивается до минуты
+var pollBackoff = time.Second
+
+// Типы обновлений, которые запрашиваем у Telegram. message — комментарии в группе обсуждения,
+// message_reaction_count — счётчики реакций (приходят, только если бот администратор канала)
+const allowedUpdates = `["channel_post","edited_channel_post","message","message_reaction_count"]`
+
+type Update struct {
+	UpdateID             int                   `json:"update_id"`
+	ChannelPost          *Message              `json:"channel_post"`
+	EditedChannelPost    *Message              `json:"edited_channel_post"`
+	Message              *Message              `json:"message"`
+	MessageReactionCount *MessageReactionCount `json:"message_reaction_count"`
+}
+
+// MessageReactionCount — новые счётчики реакций поста. Кто поставил реакцию, в каналах не видно
+type MessageReactionCount struct {
+	Chat      Chat                  `json:"chat"`
+	MessageID int                   `json:"message_id"`
+	Reactions []ReactionCountUpdate `json:"reactions"`
+}
+
+type ReactionCountUpdate struct {
+	Type struct {
+		Type          string `json:"type"` // emoji, custom_emoji или paid
+		Emoji         string `json:"emoji"`
+		CustomEmojiID string `json:"custom_emoji_id"`
+	} `json:"type"`
+	TotalCount int `json:"total_count"`
+}
+
+// Counts переводит счётчики в тот же вид, что у t.me/s и MTProto
+func (m MessageReactionCount) Counts() Reactions {
+	reactions := Reactions{}
+	for _, r := range m.Reactions {
+		emoji := r.Type.Emoji
+		switch r.Type.Type {
+		case "custom_emoji":
+			emoji = r.Type.CustomEmojiID
+		case "paid":
+			emoji = "⭐"
+		}
+		reactions.Items = append(reactions.Items, ReactionCount{Emoji: emoji, Count: r.TotalCount})
+		reactions.TotalCount += r.TotalCount
+	}
+	return reactions
+}
+
+type Chat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+}
+
+type User struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func (u User) Name() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// MessageOrigin — откуда переслано сообщение. Для постов канала Chat и MessageID — исходный пост
+type MessageOrigin struct {
+	Type      string `json:"type"`
+	Chat      *Chat  `json:"chat"`
+	MessageID int    `json:"message_id"`
+}
+
+// Message — сообщение канала или группы в формате Bot API. У постов с медиа текст лежит в Caption
+type Message struct {
+	MessageID int    `json:"message_id"`
+	Date      int    `json:"date"`
+	EditDate  int    `json:"edit_date"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+	Caption   string `json:"caption"`
+	// Поля сообщений группы обсуждения
+	From               *User          `json:"from"`
+	MessageThreadID    int            `json:"message_thread_id"`
+	IsAutomaticForward bool           `json:"is_automatic_forward"`
+	ForwardOrigin      *MessageOrigin `json:"forward_origin"`
+}
+
+func (m Message) Post() Post {
+	text := m.Text
+	if text == "" {
+		text = m.Caption
+	}
+	return Post{MessageID: m.MessageID, Date: m.Date, EditDate: m.EditDate, Text: text}
+}
+
+// GetUpdates запрашивает обновления канала начиная с offset (long polling)
+func (c *Client) GetUpdates(ctx context.Context, offset, timeout int) ([]Update, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("timeout", strconv.Itoa(timeout))
+	params.Set("allowed_updates", allowedUpdates)
+
+	body, err := c.makeRequestContext(ctx, "getUpdates", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		OK          bool     `json:"ok"`
+		Description string   `json:"description"`
+		Result      []Update `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("getUpdates: неверный ответ: %v", err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("getUpdates: %s", result.Description)
+	}
+	return result.Result, nil
+}
+
+// HandleUpdate сохраняет пост нашего канала или комментарий к нему из группы обсуждения
+func (c *Client) HandleUpdate(u Update) bool {
+	if c.Store == nil {
+		return false
+	}
+	if u.Message != nil {
+		return c.handleDiscussion(*u.Message)
+	}
+	if rc := u.MessageReactionCount; rc != nil {
+		return sameChat(rc.Chat, c.ChannelID) && c.Store.SetReactions(rc.MessageID, rc.Counts())
+	}
+
+	msg := u.ChannelPost
+	if msg == nil {
+		msg = u.EditedChannelPost
+	}
+	if msg == nil || !sameChat(msg.Chat, c.ChannelID) {
+		return false
+	}
+
+	c.Store.Upsert(msg.Post())
+	return true
+}
+
+// handleDiscussion разбирает сообщение группы обсуждения. Telegram сам пересылает туда
+// каждый пост канала — эта пересылка начинает ветку комментариев к посту.
+// Бот должен быть участником группы с выключенным privacy mode или администратором
+func (c *Client) handleDiscussion(msg Message) bool {
+	if c.DiscussionID == "" || !sameChat(msg.Chat, c.DiscussionID) {
+		return false
+	}
+
+	origin := msg.ForwardOrigin
+	if msg.IsAutomaticForward && origin != nil && origin.Chat != nil && sameChat(*origin.Chat, c.ChannelID) {
+		c.Store.LinkThread(msg.MessageID, origin.MessageID)
+		return true
+	}
+
+	if msg.From == nil || msg.MessageThreadID == 0 {
+		return false
+	}
+	postID, found := c.Store.ThreadPost(msg.MessageThreadID)
+	if !found {
+		return false
+	}
+	return c.Store.AddComment(postID, Comment{
+		MessageID: msg.MessageID,
+		Date:      msg.Date,
+		UserID:    msg.From.ID,
+		Username:  msg.From.Username,
+		Name:      msg.From.Name(),
+	})
+}
+
+// sameChat сравнивает чат с ID из настроек: числовым или @username
+func sameChat(chat Chat, id string) bool {
+	if strconv.FormatInt(chat.ID, 10) == id {
+		return true
+	}
+	return chat.Username != "" && strings.EqualFold("@"+chat.Username, id)
+}
+
+// Poll получает обновления через getUpdates, пока не отменён ctx.
+// Не работает одновременно с вебхуком — Telegram вернёт ошибку
+func (c *Client) Poll(ctx context.Context) {
+	backoff := pollBackoff
+	for ctx.Err() == nil {
+		updates, err := c.GetUpdates(ctx, c.Store.Offset(), pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			fmt.Printf("❌ Telegram getUpdates: %v (повтор через %v)\n", err, backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = pollBackoff
+
+		if len(updates) == 0 {
+			continue
+		}
+		saved := 0
+		for _, u := range updates {
+			if c.HandleUpdate(u) {
+				saved++
+			}
+			c.Store.SetOffset(u.UpdateID + 1)
+		}
+		if err := c.Store.Save(); err != nil {
+			fmt.Println("❌ Сохранение постов Telegram:", err)
+		}
+		if saved > 0 {
+			fmt.Printf("✈️  Telegram: сохранено постов и комментариев %d\n", saved)
+		}
+	}
+}
+
+// SetWebhook регистрирует вебхук. secret приходит в заголовке X-Telegram-Bot-Api-Secret-Token
+func (c *Client) SetWebhook(webhookURL, secret string) error {
+	params := url.Values{}
+	params.Set("url", webhookURL)
+	params.Set("secret_token", secret)
+	params.Set("allowed_updates", allowedUpdates)
+
+	body, err := c.makeRequest("setWebhook", params)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	json.Unmarshal(body, &result)
+	if !result.OK {
+		return fmt.Errorf("setWebhook: %s", result.Description)
+	}
+	return nil
+}
+
+// WebhookHandler принимает обновления от Telegram. Запросы без верного секрета отклоняются
+func (c *Client) WebhookHandler(secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var u Update
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		if c.HandleUpdate(u) {
+			if err := c.Store.Save(); err != nil {
+				fmt.Println("❌ Сохранение постов Telegram:", err)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,223 @@
+package tg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testChannel = "@smm_channel"
+
+func newTestClient(t *testing.T, baseURL string) (*Client, string) {
+	path := filepath.Join(t.TempDir(), "tg_posts.json")
+	store, err := NewPostStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient("token", testChannel)
+	c.BaseURL = baseURL
+	c.Store = store
+	return c, path
+}
+
+// Обновления: пост нашего канала, пост чужого канала и правка нашего поста
+const firstBatch = `{"ok":true,"result":[
+	{"update_id":5,"channel_post":{"message_id":10,"date":1700000000,"chat":{"id":-1001,"type":"channel","username":"smm_channel"},"text":"Первый пост"}},
+	{"update_id":6,"channel_post":{"message_id":11,"date":1700000100,"chat":{"id":-1002,"type":"channel","username":"other"},"text":"Чужой пост"}},
+	{"update_id":7,"channel_post":{"message_id":12,"date":1700000200,"chat":{"id":-1001,"type":"channel","username":"SMM_Channel"},"caption":"Фото с подписью"}},
+	{"update_id":8,"edited_channel_post":{"message_id":10,"date":1700000000,"edit_date":1700000500,"chat":{"id":-1001,"type":"channel","username":"smm_channel"},"text":"Первый пост (исправлен)"}},
+	{"update_id":9,"edited_channel_post":{"message_id":11,"date":1700000100,"edit_date":1700000600,"chat":{"id":-1002,"type":"channel","username":"other"},"text":"Чужая правка"}}
+]}`
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	offsets := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/getUpdates" {
+			t.Errorf("неожиданный запрос %s", r.URL.Path)
+		}
+		if !strings.Contains(r.URL.Query().Get("allowed_updates"), "edited_channel_post") {
+			t.Errorf("allowed_updates = %s", r.URL.Query().Get("allowed_updates"))
+		}
+		mu.Lock()
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		n := len(offsets)
+		mu.Unlock()
+
+		if n == 1 {
+			w.Write([]byte(firstBatch))
+			return
+		}
+		// Второй запрос — пакет уже сохранён, можно заканчивать
+		cancel()
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, path := newTestClient(t, srv.URL)
+	runPoll(t, ctx, c)
+
+	if len(offsets) < 2 || offsets[0] != "0" || offsets[1] != "10" {
+		t.Errorf("offset запросов %v, ожидалось [0 10]", offsets)
+	}
+
+	// Пакет сохранён на диск вместе с offset — после перезапуска обновления не придут повторно
+	reloaded, err := NewPostStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Offset() != 10 {
+		t.Errorf("сохранённый offset = %d, ожидалось 10", reloaded.Offset())
+	}
+	if reloaded.Len() != 2 {
+		t.Errorf("сохранено постов %d, ожидалось 2 (только наш канал)", reloaded.Len())
+	}
+	if p, _ := reloaded.Get(10); p.Text != "Первый пост (исправлен)" || p.EditDate != 1700000500 {
+		t.Errorf("пост 10 = %+v, ожидалась правка", p)
+	}
+	if p, _ := reloaded.Get(12); p.Text != "Фото с подписью" {
+		t.Errorf("пост 12 = %+v, ожидался текст из подписи", p)
+	}
+	if _, found := reloaded.Get(11); found {
+		t.Error("сохранён пост чужого канала")
+	}
+}
+
+func TestPollBackoff(t *testing.T) {
+	defer func(b time.Duration) { pollBackoff = b }(pollBackoff)
+	pollBackoff = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	times := []time.Time{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		n := len(times)
+		mu.Unlock()
+
+		switch n {
+		case 1, 2:
+			w.Write([]byte(`{"ok":false,"description":"Bad Gateway"}`))
+		case 3:
+			w.Write([]byte(`not json`))
+		default:
+			cancel()
+			w.Write([]byte(`{"ok":true,"result":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(t, srv.URL)
+	runPoll(t, ctx, c)
+
+	if len(times) < 4 {
+		t.Fatalf("запросов %d, ожидалось 4", len(times))
+	}
+	// Пауза после каждой ошибки вдвое длиннее предыдущей
+	for i, min := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond} {
+		if gap := times[i+1].Sub(times[i]); gap < min {
+			t.Errorf("пауза после ошибки %d = %v, ожидалось не меньше %v", i+1, gap, min)
+		}
+	}
+}
+
+func runPoll(t *testing.T, ctx context.Context, c *Client) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		c.Poll(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Poll не остановился после отмены контекста")
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	update := `{"update_id":1,"channel_post":{"message_id":20,"date":1700000000,"chat":{"id":-1001,"type":"channel","username":"smm_channel"},"text":"Пост"}}`
+
+	tests := []struct {
+		name   string
+		secret string // в настройках
+		header string // в запросе
+		body   string
+		status int
+		saved  bool
+	}{
+		{"верный секрет", "s3cret", "s3cret", update, http.StatusOK, true},
+		{"неверный секрет", "s3cret", "wrong", update, http.StatusForbidden, false},
+		{"без заголовка", "s3cret", "", update, http.StatusForbidden, false},
+		{"секрет не настроен", "", "", update, http.StatusForbidden, false},
+		{"неверный JSON", "s3cret", "s3cret", "{", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, path := newTestClient(t, "http://127.0.0.1:0")
+			req := httptest.NewRequest("POST", "/tg/webhook", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c.WebhookHandler(tt.secret)(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("статус %d, ожидался %d", rec.Code, tt.status)
+			}
+			reloaded, _ := NewPostStore(path)
+			if _, found := reloaded.Get(20); found != tt.saved {
+				t.Errorf("пост сохранён: %v, ожидалось %v", found, tt.saved)
+			}
+		})
+	}
+}
+
+// Правка поста меняет текст, но не стирает реакции из message_reaction_count
+func TestHandleUpdateEditKeepsReactions(t *testing.T) {
+	c, _ := newTestClient(t, "http://127.0.0.1:0")
+	chat := Chat{ID: -1001, Type: "channel", Username: "smm_channel"}
+
+	c.HandleUpdate(Update{ChannelPost: &Message{MessageID: 30, Date: 1700000000, Chat: chat, Text: "Черновик"}})
+	rc := MessageReactionCount{Chat: chat, MessageID: 30}
+	rc.Reactions = make([]ReactionCountUpdate, 2)
+	rc.Reactions[0].Type.Type, rc.Reactions[0].Type.Emoji, rc.Reactions[0].TotalCount = "emoji", "👍", 5
+	rc.Reactions[1].Type.Type, rc.Reactions[1].TotalCount = "paid", 2
+	if !c.HandleUpdate(Update{MessageReactionCount: &rc}) {
+		t.Fatal("реакции не сохранены")
+	}
+
+	// Просмотры могли прийти из другого источника — правка их тоже не обнуляет
+	c.Store.mu.Lock()
+	p := c.Store.data.Posts[30]
+	p.Views, p.Forwards = 120, 3
+	c.Store.data.Posts[30] = p
+	c.Store.mu.Unlock()
+
+	c.HandleUpdate(Update{EditedChannelPost: &Message{MessageID: 30, Date: 1700000000, EditDate: 1700000900, Chat: chat, Text: "Итоговый текст"}})
+
+	got, _ := c.Store.Get(30)
+	if got.Text != "Итоговый текст" || got.EditDate != 1700000900 {
+		t.Errorf("правка не применена: %+v", got)
+	}
+	want := Reactions{TotalCount: 7, Items: []ReactionCount{{Emoji: "👍", Count: 5}, {Emoji: "⭐", Count: 2}}}
+	if !reflect.DeepEqual(got.Reactions, want) {
+		t.Errorf("реакции после правки %+v, ожидалось %+v", got.Reactions, want)
+	}
+	if got.Views != 120 || got.Forwards != 3 {
+		t.Errorf("просмотры и пересылки после правки: %d, %d", got.Views, got.Forwards)
+	}
+}
@@ -20,7 +20,9 @@ const (
 
 type Client struct {
 	AccessToken string
-	httpClient  *http.Client
+	// APIURL можно подменить на тестовый сервер
+	APIURL     string
+	httpClient *http.Client
 }
 
 type Group struct {
@@ -29,20 +31,73 @@ type Group struct {
 }
 
 type Employee struct {
-	ID     int
-	Name   string
-	URL    string
-	Domain string
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Domain string `json:"domain"`
 }
 
 type Post struct {
 	ID       int    `json:"id"`
+	OwnerID  int    `json:"owner_id,omitempty"`
 	Date     int    `json:"date"`
 	Text     string `json:"text"`
 	Views    Views  `json:"views"`
 	Likes    Count  `json:"likes"`
 	Reposts  Count  `json:"reposts"`
 	Comments Count  `json:"comments"`
+	// SignerID — автор подписанного поста
+	SignerID int `json:"signer_id,omitempty"`
+	// Вложения и репост — для кросспостинга
+	Attachments []Attachment `json:"attachments,omitempty"`
+	CopyHistory []Post       `json:"copy_history,omitempty"`
+}
+
+// Attachment — вложение поста. Заполнено поле, соответствующее Type
+type Attachment struct {
+	Type  string `json:"type"` // photo, video, link, doc...
+	Photo *Photo `json:"photo,omitempty"`
+	Video *Video `json:"video,omitempty"`
+	Link  *Link  `json:"link,omitempty"`
+}
+
+type Photo struct {
+	ID      int         `json:"id"`
+	OwnerID int         `json:"owner_id"`
+	Sizes   []PhotoSize `json:"sizes"`
+}
+
+type PhotoSize struct {
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// URL — ссылка на самую большую копию фото
+func (p Photo) URL() string {
+	best, area := "", -1
+	for _, s := range p.Sizes {
+		if s.Width*s.Height > area {
+			best, area = s.URL, s.Width*s.Height
+		}
+	}
+	return best
+}
+
+type Video struct {
+	ID      int    `json:"id"`
+	OwnerID int    `json:"owner_id"`
+	Title   string `json:"title"`
+}
+
+func (v Video) URL() string {
+	return fmt.Sprintf("https://vk.com/video%d_%d", v.OwnerID, v.ID)
+}
+
+type Link struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
 }
 
 type Views struct {
@@ -54,9 +109,9 @@ type Count struct {
 }
 
 type ActivityStats struct {
-	Likes   int
-	Reposts int
-	Total   int
+	Likes   int `json:"likes"`
+	Reposts int `json:"reposts"`
+	Total   int `json:"total"`
 }
 
 func NewClient(token string) *Client {
@@ -68,6 +123,7 @@ func NewClient(token string) *Client {
 	}
 	return &Client{
 		AccessToken: token,
+		APIURL:      apiURL,
 		httpClient: &http.Client{
 			Transport: tr,
 			Timeout:   10 * time.Second, // ← УСКОРЕНИЕ (было 20)
@@ -79,7 +135,7 @@ func (c *Client) makeRequest(method string, params url.Values) ([]byte, error) {
 	params.Set("access_token", c.AccessToken)
 	params.Set("v", apiVersion)
 
-	resp, err := c.httpClient.Get(apiURL + method + "?" + params.Encode())
+	resp, err := c.httpClient.Get(c.endpoint(method) + "?" + params.Encode())
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +143,14 @@ func (c *Client) makeRequest(method string, params url.Values) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// endpoint — адрес метода API
+func (c *Client) endpoint(method string) string {
+	if !strings.HasSuffix(c.APIURL, "/") {
+		return c.APIURL + "/" + method
+	}
+	return c.APIURL + method
+}
+
 func (c *Client) GetGroupByDomain(domain string) (*Group, error) {
 	params := url.Values{}
 	params.Set("group_id", domain)
@@ -0,0 +1,126 @@
+package vk
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Типы событий Callback API, которые обрабатывает приложение
+const (
+	EventConfirmation = "confirmation"
+	EventWallPostNew  = "wall_post_new"
+	EventWallRepost   = "wall_repost"
+	EventWallReplyNew = "wall_reply_new"
+	EventLikeAdd      = "like_add"
+	EventLikeRemove   = "like_remove"
+)
+
+// VK повторяет событие, если не получил «ok». Повторы с тем же event_id пропускаем
+const eventRetention = time.Hour
+
+// Event — событие Callback API. Object разбирается методами под тип события
+type Event struct {
+	Type    string          `json:"type"`
+	GroupID int             `json:"group_id"`
+	EventID string          `json:"event_id"`
+	Secret  string          `json:"secret"`
+	Object  json.RawMessage `json:"object"`
+}
+
+// WallPost — пост из wall_post_new и wall_repost. У репоста оригинал лежит в CopyHistory,
+// а OwnerID — стена, на которую сделан репост
+type WallPost struct {
+	Post
+	FromID   int    `json:"from_id"`
+	PostType string `json:"post_type"` // post, suggest (предложенный), postpone (отложенный)
+}
+
+// Like — like_add и like_remove
+type Like struct {
+	LikerID       int    `json:"liker_id"`
+	ObjectType    string `json:"object_type"` // post, comment, photo...
+	ObjectOwnerID int    `json:"object_owner_id"`
+	ObjectID      int    `json:"object_id"`
+}
+
+// Reply — комментарий из wall_reply_new
+type Reply struct {
+	ID          int    `json:"id"`
+	FromID      int    `json:"from_id"`
+	Date        int    `json:"date"`
+	Text        string `json:"text"`
+	PostID      int    `json:"post_id"`
+	PostOwnerID int    `json:"post_owner_id"`
+}
+
+func (e Event) WallPost() (WallPost, error) {
+	var p WallPost
+	err := json.Unmarshal(e.Object, &p)
+	return p, err
+}
+
+func (e Event) Like() (Like, error) {
+	var l Like
+	err := json.Unmarshal(e.Object, &l)
+	return l, err
+}
+
+func (e Event) Reply() (Reply, error) {
+	var r Reply
+	err := json.Unmarshal(e.Object, &r)
+	return r, err
+}
+
+// CallbackHandler принимает события Callback API сообщества groupID (положительный ID).
+// На confirmation отвечает строкой подтверждения из настроек сервера в VK,
+// события с неверным секретом отклоняет. handle вызывается один раз на event_id
+func CallbackHandler(groupID int, confirmation, secret string, handle func(Event) error) http.HandlerFunc {
+	seen := map[string]time.Time{}
+	var mu sync.Mutex
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if e.GroupID != groupID {
+			http.Error(w, "unknown group", http.StatusForbidden)
+			return
+		}
+
+		if e.Type == EventConfirmation {
+			fmt.Fprint(w, confirmation)
+			return
+		}
+		if secret == "" || subtle.ConstantTimeCompare([]byte(e.Secret), []byte(secret)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		now := time.Now()
+		for id, at := range seen {
+			if now.Sub(at) > eventRetention {
+				delete(seen, id)
+			}
+		}
+		_, duplicate := seen[e.EventID]
+		if e.EventID != "" {
+			seen[e.EventID] = now
+		}
+		mu.Unlock()
+
+		if !duplicate {
+			if err := handle(e); err != nil {
+				fmt.Printf("❌ VK Callback %s: %v\n", e.Type, err)
+			}
+		}
+		// Ответ не «ok» VK считает ошибкой и присылает событие снова
+		fmt.Fprint(w, "ok")
+	}
+}
@@ -0,0 +1,182 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LongPoll получает события сообщества через Bots Long Poll API — для серверов без
+// публичного адреса, где Callback API недоступен. События те же, что у Callback API
+type LongPoll struct {
+	// Token — ключ сообщества с доступом к управлению или токен администратора
+	Token   string
+	GroupID int // положительный ID сообщества
+	// APIURL можно подменить на тестовый сервер, адрес long poll сервера приходит от него же
+	APIURL string
+	// Wait — сколько секунд сервер держит запрос, если событий нет (максимум 90)
+	Wait int
+
+	httpClient *http.Client
+	server     longPollServer
+}
+
+type longPollServer struct {
+	Key    string `json:"key"`
+	Server string `json:"server"`
+	// ts приходит то строкой, то числом
+	TS json.Number `json:"ts"`
+}
+
+func NewLongPoll(token string, groupID int) *LongPoll {
+	return &LongPoll{
+		Token:   token,
+		GroupID: groupID,
+		APIURL:  apiURL,
+		Wait:    25,
+	}
+}
+
+// Run получает события и передаёт их в handle, пока не отменён ctx.
+// Истёкший ключ и потерянную историю событий обрабатывает сам, при ошибках сети ждёт и повторяет
+func (lp *LongPoll) Run(ctx context.Context, handle func(Event) error) {
+	lp.httpClient = &http.Client{Timeout: time.Duration(lp.Wait+15) * time.Second}
+
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := lp.poll(ctx, handle)
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("❌ VK Long Poll: %v (повтор через %v)\n", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// poll делает один запрос a_check. Без сервера сначала запрашивает его
+func (lp *LongPoll) poll(ctx context.Context, handle func(Event) error) error {
+	if lp.server.Key == "" {
+		server, err := lp.getServer(ctx)
+		if err != nil {
+			return err
+		}
+		// После истёкшего ключа продолжаем со своего ts, чтобы не потерять события
+		if lp.server.TS != "" {
+			server.TS = lp.server.TS
+		}
+		lp.server = server
+	}
+
+	params := url.Values{}
+	params.Set("act", "a_check")
+	params.Set("key", lp.server.Key)
+	params.Set("ts", lp.server.TS.String())
+	params.Set("wait", strconv.Itoa(lp.Wait))
+
+	body, err := lp.get(ctx, lp.server.Server+"?"+params.Encode())
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		TS      json.Number `json:"ts"`
+		Updates []Event     `json:"updates"`
+		Failed  int         `json:"failed"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("неверный ответ: %v", err)
+	}
+
+	switch result.Failed {
+	case 0:
+	case 1:
+		// Часть событий потеряна — продолжаем с нового ts
+		lp.server.TS = result.TS
+		return nil
+	case 2:
+		// Ключ истёк — нужен новый, ts остаётся прежним
+		lp.server.Key = ""
+		return nil
+	case 3:
+		// Информация о событиях потеряна — новый ключ и ts
+		lp.server = longPollServer{}
+		return nil
+	default:
+		return fmt.Errorf("failed=%d", result.Failed)
+	}
+
+	for _, e := range result.Updates {
+		if err := handle(e); err != nil {
+			fmt.Printf("❌ VK Long Poll %s: %v\n", e.Type, err)
+		}
+	}
+	lp.server.TS = result.TS
+	return nil
+}
+
+func (lp *LongPoll) getServer(ctx context.Context) (longPollServer, error) {
+	params := url.Values{}
+	params.Set("group_id", strconv.Itoa(lp.GroupID))
+	params.Set("access_token", lp.Token)
+	params.Set("v", apiVersion)
+
+	endpoint := lp.APIURL
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	body, err := lp.get(ctx, endpoint+"groups.getLongPollServer?"+params.Encode())
+	if err != nil {
+		return longPollServer{}, err
+	}
+
+	var result struct {
+		Response *longPollServer `json:"response"`
+		Error    *struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"error_msg"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return longPollServer{}, fmt.Errorf("groups.getLongPollServer: неверный ответ: %v", err)
+	}
+	if result.Error != nil {
+		return longPollServer{}, fmt.Errorf("groups.getLongPollServer: %d %s", result.Error.Code, result.Error.Message)
+	}
+	if result.Response == nil || result.Response.Server == "" {
+		return longPollServer{}, fmt.Errorf("groups.getLongPollServer: пустой ответ")
+	}
+	return *result.Response, nil
+}
+
+func (lp *LongPoll) get(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := lp.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,135 @@
+package vk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLongPoll — groups.getLongPollServer и long poll сервер с заранее заданными ответами a_check
+type fakeLongPoll struct {
+	*httptest.Server
+	t      *testing.T
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	servers    []string // ответы groups.getLongPollServer: key и ts
+	checks     []string // ответы a_check
+	handshakes int
+	requests   []string // key и ts каждого a_check
+}
+
+func newFakeLongPoll(t *testing.T, cancel context.CancelFunc, servers, checks []string) *fakeLongPoll {
+	f := &fakeLongPoll{t: t, cancel: cancel, servers: servers, checks: checks}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/method/groups.getLongPollServer", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("group_id") != "123" || r.FormValue("access_token") != "token" {
+			t.Errorf("getLongPollServer: group_id=%s", r.FormValue("group_id"))
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.handshakes >= len(f.servers) {
+			t.Errorf("лишний запрос groups.getLongPollServer")
+			return
+		}
+		fmt.Fprintf(w, `{"response":{"server":"%s/lp",%s}}`, f.URL, f.servers[f.handshakes])
+		f.handshakes++
+	})
+	mux.HandleFunc("/lp", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("act") != "a_check" {
+			t.Errorf("act=%s", r.FormValue("act"))
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.FormValue("key")+" "+r.FormValue("ts"))
+		n := len(f.requests)
+		if n > len(f.checks) {
+			// Сценарий закончился
+			f.cancel()
+			w.Write([]byte(`{"ts":"0","updates":[]}`))
+			return
+		}
+		w.Write([]byte(f.checks[n-1]))
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestLongPollRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := newFakeLongPoll(t, cancel,
+		[]string{
+			`"key":"k1","ts":"10"`,
+			`"key":"k2","ts":"99"`, // после failed=2 ts остаётся прежним
+			`"key":"k3","ts":500`,  // после failed=3 — всё новое, ts числом
+		},
+		[]string{
+			`{"ts":"11","updates":[{"type":"wall_post_new","group_id":123,"event_id":"e1","object":{"id":5,"text":"Пост"}}]}`,
+			`{"failed":1,"ts":"20"}`,
+			`{"failed":2}`,
+			`{"ts":21,"updates":[{"type":"like_add","group_id":123,"event_id":"e2","object":{"liker_id":7,"object_type":"post","object_id":5}},{"type":"wall_reply_new","group_id":123,"event_id":"e3","object":{"id":1,"post_id":5}}]}`,
+			`{"failed":3}`,
+		})
+
+	lp := NewLongPoll("token", 123)
+	lp.APIURL = f.URL + "/method"
+	lp.Wait = 1
+
+	events := []string{}
+	done := make(chan struct{})
+	go func() {
+		lp.Run(ctx, func(e Event) error {
+			events = append(events, e.Type+" "+e.EventID)
+			return nil
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run не остановился после отмены контекста")
+	}
+
+	wantRequests := []string{
+		"k1 10", // после рукопожатия
+		"k1 11", // ts сдвинулся после пакета
+		"k1 20", // failed=1: новый ts из ответа
+		"k2 20", // failed=2: новый ключ, ts прежний
+		"k2 21",
+		"k3 500", // failed=3: новые ключ и ts
+	}
+	if !reflect.DeepEqual(f.requests, wantRequests) {
+		t.Errorf("a_check:\n получено %v\nожидалось %v", f.requests, wantRequests)
+	}
+	if f.handshakes != 3 {
+		t.Errorf("groups.getLongPollServer вызван %d раз, ожидалось 3", f.handshakes)
+	}
+	wantEvents := []string{"wall_post_new e1", "like_add e2", "wall_reply_new e3"}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("события %v, ожидалось %v", events, wantEvents)
+	}
+}
+
+// Ошибка VK при рукопожатии возвращается с кодом и текстом — Run выведет её и повторит запрос
+func TestLongPollServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"error_code":15,"error_msg":"Access denied: group messages are disabled"}}`))
+	}))
+	defer srv.Close()
+
+	lp := NewLongPoll("token", 123)
+	lp.APIURL = srv.URL
+	lp.httpClient = srv.Client()
+	_, err := lp.getServer(context.Background())
+	if err == nil || err.Error() != "groups.getLongPollServer: 15 Access denied: group messages are disabled" {
+		t.Errorf("ошибка %v", err)
+	}
+}
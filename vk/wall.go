@@ -0,0 +1,196 @@
+package vk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Draft — пост для публикации или правки от имени сообщества
+type Draft struct {
+	Message string
+	// Attachments — вложения в формате VK, например photo-1_456239017
+	Attachments []string
+	// PublishDate — время отложенной публикации, нулевое — опубликовать сразу
+	PublishDate time.Time
+	// Signed — подписать пост автором
+	Signed bool
+}
+
+// ID вложения в формате <тип><владелец>_<id> для wall.post и wall.edit, у ссылки — её адрес.
+// Пустая строка — вложение, которое приложение не умеет передать обратно
+func (a Attachment) ID() string {
+	switch {
+	case a.Photo != nil:
+		return fmt.Sprintf("photo%d_%d", a.Photo.OwnerID, a.Photo.ID)
+	case a.Video != nil:
+		return fmt.Sprintf("video%d_%d", a.Video.OwnerID, a.Video.ID)
+	case a.Link != nil:
+		return a.Link.URL
+	}
+	return ""
+}
+
+func (d Draft) params(params url.Values) url.Values {
+	params.Set("message", d.Message)
+	params.Set("attachments", strings.Join(d.Attachments, ","))
+	if d.Signed {
+		params.Set("signed", "1")
+	} else {
+		params.Set("signed", "0")
+	}
+	if !d.PublishDate.IsZero() {
+		params.Set("publish_date", strconv.FormatInt(d.PublishDate.Unix(), 10))
+	}
+	return params
+}
+
+// Publish публикует пост на стене сообщества ownerID (отрицательный ID) и возвращает его ID.
+// С PublishDate пост попадает в отложенные
+func (c *Client) Publish(ownerID int, d Draft) (int, error) {
+	params := url.Values{}
+	params.Set("owner_id", strconv.Itoa(ownerID))
+	params.Set("from_group", "1")
+
+	var result struct {
+		PostID int `json:"post_id"`
+	}
+	if err := c.call("wall.post", d.params(params), &result); err != nil {
+		return 0, err
+	}
+	return result.PostID, nil
+}
+
+// EditPost меняет пост, в том числе отложенный: у него можно перенести время публикации
+func (c *Client) EditPost(ownerID, postID int, d Draft) error {
+	params := url.Values{}
+	params.Set("owner_id", strconv.Itoa(ownerID))
+	params.Set("post_id", strconv.Itoa(postID))
+
+	var result struct {
+		PostID int `json:"post_id"`
+	}
+	return c.call("wall.edit", d.params(params), &result)
+}
+
+func (c *Client) DeletePost(ownerID, postID int) error {
+	params := url.Values{}
+	params.Set("owner_id", strconv.Itoa(ownerID))
+	params.Set("post_id", strconv.Itoa(postID))
+
+	var result int
+	return c.call("wall.delete", params, &result)
+}
+
+// GetPostponed — отложенные посты сообщества, ближайшие первыми
+func (c *Client) GetPostponed(ownerID, count int) ([]Post, error) {
+	params := url.Values{}
+	params.Set("owner_id", strconv.Itoa(ownerID))
+	params.Set("filter", "postponed")
+	params.Set("count", strconv.Itoa(count))
+
+	var result struct {
+		Items []Post `json:"items"`
+	}
+	if err := c.call("wall.get", params, &result); err != nil {
+		return nil, err
+	}
+	sort.Slice(result.Items, func(i, j int) bool { return result.Items[i].Date < result.Items[j].Date })
+	return result.Items, nil
+}
+
+// UploadWallPhoto загружает фото для поста сообщества groupID (положительный ID):
+// photos.getWallUploadServer → загрузка файла → photos.saveWallPhoto. Возвращает ID вложения
+func (c *Client) UploadWallPhoto(groupID int, filename string, data []byte) (string, error) {
+	params := url.Values{}
+	params.Set("group_id", strconv.Itoa(groupID))
+
+	var server struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := c.call("photos.getWallUploadServer", params, &server); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("photo", filename)
+	if err != nil {
+		return "", err
+	}
+	part.Write(data)
+	mw.Close()
+
+	resp, err := c.httpClient.Post(server.UploadURL, mw.FormDataContentType(), &buf)
+	if err != nil {
+		return "", fmt.Errorf("загрузка фото: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var uploaded struct {
+		Server int    `json:"server"`
+		Photo  string `json:"photo"`
+		Hash   string `json:"hash"`
+	}
+	if err := json.Unmarshal(body, &uploaded); err != nil || uploaded.Photo == "" || uploaded.Photo == "[]" {
+		return "", fmt.Errorf("загрузка фото %s: сервер VK не принял файл", filename)
+	}
+
+	params = url.Values{}
+	params.Set("group_id", strconv.Itoa(groupID))
+	params.Set("server", strconv.Itoa(uploaded.Server))
+	params.Set("photo", uploaded.Photo)
+	params.Set("hash", uploaded.Hash)
+
+	var saved []Photo
+	if err := c.call("photos.saveWallPhoto", params, &saved); err != nil {
+		return "", err
+	}
+	if len(saved) == 0 {
+		return "", fmt.Errorf("photos.saveWallPhoto: пустой ответ")
+	}
+	return Attachment{Photo: &saved[0]}.ID(), nil
+}
+
+// call вызывает метод POST запросом (текст поста может быть длинным) и возвращает ошибку VK,
+// если она есть. Ответ разбирается в v
+func (c *Client) call(method string, params url.Values, v interface{}) error {
+	params.Set("access_token", c.AccessToken)
+	params.Set("v", apiVersion)
+
+	resp, err := c.httpClient.PostForm(c.endpoint(method), params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Response json.RawMessage `json:"response"`
+		Error    *struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"error_msg"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("%s: неверный ответ: %v", method, err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("%s: %d %s", method, result.Error.Code, result.Error.Message)
+	}
+	return json.Unmarshal(result.Response, v)
+}
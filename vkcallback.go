@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"smm-helper/vk"
+)
+
+// ========== СОБЫТИЯ VK: CALLBACK API И LONG POLL ==========
+
+// Кэши, которые устаревают при новом посте, лайке, репосте или комментарии
+var vkCachePrefixes = []string{"posts_analysis_", "api_posts_stats_", "api_analysis_vk_", "overview_", "digest_"}
+
+// wallState — последние посты стены с лайками и репостами. Загружается из VK целиком,
+// а между загрузками его обновляют события Callback API или Long Poll
+type wallState struct {
+	posts   []vk.Post // от новых к старым
+	likes   map[int][]int
+	reposts map[int][]int
+	loaded  time.Time
+	mu      sync.Mutex
+	// load пропускает одну перезагрузку за раз. Запросы к VK идут без mu,
+	// чтобы события не ждали их и VK быстро получал ответ на Callback API
+	load sync.Mutex
+}
+
+var vkWall = &wallState{}
+
+func initVKCallback() {
+	vc := cfg.VKCallback
+	if vc.Enabled && (vc.Confirmation == "" || vc.Secret == "") {
+		log.Fatal("Для Callback API VK задайте vk_callback.confirmation и secret из настроек сообщества")
+	}
+	// Оба источника присылают одни и те же события — репосты и комментарии посчитались бы дважды
+	if vc.Enabled && cfg.VKLongPoll.Enabled {
+		log.Fatal("Включите что-то одно: vk_callback или vk_longpoll")
+	}
+}
+
+// vkEventsEnabled — стену обновляют события VK
+func vkEventsEnabled() bool {
+	return cfg.VKCallback.Enabled || cfg.VKLongPoll.Enabled
+}
+
+// startVKLongPoll запускает получение событий через Bots Long Poll, если он включён
+func startVKLongPoll() {
+	lc := cfg.VKLongPoll
+	if !lc.Enabled {
+		return
+	}
+	token := lc.Token
+	if token == "" {
+		token = VK_ACCESS_TOKEN
+	}
+
+	lp := vk.NewLongPoll(token, -groupID)
+	if lc.APIURL != "" {
+		lp.APIURL = lc.APIURL
+	}
+	if lc.WaitSeconds > 0 {
+		lp.Wait = lc.WaitSeconds
+	}
+	fmt.Printf("📨 VK Long Poll: сообщество %d, ожидание %d с\n", -groupID, lp.Wait)
+	go lp.Run(context.Background(), handleVKEvent)
+}
+
+// wallTTL — как долго загруженная стена считается свежей. С Callback API или Long Poll события
+// держат её актуальной, перезагрузка нужна только на случай пропущенных событий
+func wallTTL() time.Duration {
+	if vkEventsEnabled() {
+		return 30 * time.Minute
+	}
+	return 5 * time.Minute
+}
+
+// Get возвращает count последних постов и копии лайков и репостов к ним
+func (s *wallState) Get(count int) ([]vk.Post, map[int][]int, map[int][]int, error) {
+	if s.fresh(count) {
+		fmt.Printf("📦 Из кэша (%d постов)\n", count)
+	} else {
+		s.load.Lock()
+		// Пока ждали, стену мог загрузить параллельный запрос
+		if !s.fresh(count) {
+			if err := s.reload(count); err != nil {
+				s.load.Unlock()
+				return nil, nil, nil, err
+			}
+		}
+		s.load.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := count
+	if n > len(s.posts) {
+		n = len(s.posts)
+	}
+	posts := append([]vk.Post{}, s.posts[:n]...)
+	likes := map[int][]int{}
+	reposts := map[int][]int{}
+	for _, p := range posts {
+		likes[p.ID] = append([]int{}, s.likes[p.ID]...)
+		reposts[p.ID] = append([]int{}, s.reposts[p.ID]...)
+	}
+	return posts, likes, reposts, nil
+}
+
+func (s *wallState) fresh(count int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.loaded) <= wallTTL() && len(s.posts) >= count
+}
+
+// reload загружает стену из VK и подменяет её целиком. События, пришедшие во время
+// загрузки, меняют старую стену — новая их уже учитывает, а пропущенное поправит следующая загрузка
+func (s *wallState) reload(count int) error {
+	fmt.Printf("🔄 Загрузка с VK (%d постов)...\n", count)
+	startTime := time.Now()
+
+	posts, err := vkClient.GetWallPosts(groupID, count)
+	if err != nil {
+		return err
+	}
+	postIDs := []int{}
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	likes, reposts := vkClient.GetLikesAndRepostsParallel(groupID, postIDs)
+
+	s.mu.Lock()
+	s.posts, s.likes, s.reposts, s.loaded = posts, likes, reposts, time.Now()
+	s.mu.Unlock()
+	fmt.Printf("✅ Загружено за %v\n", time.Since(startTime))
+	return nil
+}
+
+func (s *wallState) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.posts, s.likes, s.reposts, s.loaded = nil, nil, nil, time.Time{}
+}
+
+// update меняет загруженный пост. false — поста нет среди загруженных
+func (s *wallState) update(postID int, fn func(p *vk.Post)) bool {
+	for i := range s.posts {
+		if s.posts[i].ID == postID {
+			fn(&s.posts[i])
+			return true
+		}
+	}
+	return false
+}
+
+func (s *wallState) AddPost(p vk.Post) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded.IsZero() || s.update(p.ID, func(*vk.Post) {}) {
+		return
+	}
+	s.posts = append([]vk.Post{p}, s.posts...)
+}
+
+func (s *wallState) SetLike(postID, userID int, liked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.update(postID, func(p *vk.Post) {
+		had := contains(s.likes[postID], userID)
+		switch {
+		case liked && !had:
+			s.likes[postID] = append(s.likes[postID], userID)
+			p.Likes.Count++
+		case !liked && had:
+			s.likes[postID] = without(s.likes[postID], userID)
+			p.Likes.Count--
+		}
+	})
+}
+
+func (s *wallState) AddRepost(postID, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.update(postID, func(p *vk.Post) {
+		if !contains(s.reposts[postID], userID) {
+			s.reposts[postID] = append(s.reposts[postID], userID)
+		}
+		p.Reposts.Count++
+	})
+}
+
+func (s *wallState) AddComment(postID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.update(postID, func(p *vk.Post) {
+		p.Comments.Count++
+	})
+}
+
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func without(ids []int, id int) []int {
+	result := []int{}
+	for _, v := range ids {
+		if v != id {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// handleVKEvent применяет событие к загруженной стене и сбрасывает устаревшие кэши
+func handleVKEvent(e vk.Event) error {
+	switch e.Type {
+	case vk.EventWallPostNew:
+		post, err := e.WallPost()
+		if err != nil {
+			return err
+		}
+		if post.PostType != "" && post.PostType != "post" {
+			return nil
+		}
+		vkWall.AddPost(post.Post)
+		fmt.Printf("📨 VK: новый пост %d\n", post.ID)
+		if crossPoster != nil {
+			go func() {
+				if _, err := crossPoster.Handle(context.Background(), post.Post); err != nil {
+					fmt.Printf("❌ Кросспостинг поста %d: %v\n", post.ID, err)
+				}
+			}()
+		}
+
+	case vk.EventLikeAdd, vk.EventLikeRemove:
+		like, err := e.Like()
+		if err != nil {
+			return err
+		}
+		if like.ObjectType != "post" || like.ObjectOwnerID != groupID {
+			return nil
+		}
+		vkWall.SetLike(like.ObjectID, like.LikerID, e.Type == vk.EventLikeAdd)
+
+	case vk.EventWallRepost:
+		repost, err := e.WallPost()
+		if err != nil {
+			return err
+		}
+		if len(repost.CopyHistory) == 0 || repost.CopyHistory[0].OwnerID != groupID {
+			return nil
+		}
+		vkWall.AddRepost(repost.CopyHistory[0].ID, repost.OwnerID)
+
+	case vk.EventWallReplyNew:
+		reply, err := e.Reply()
+		if err != nil {
+			return err
+		}
+		if reply.PostOwnerID != groupID {
+			return nil
+		}
+		vkWall.AddComment(reply.PostID)
+
+	default:
+		return nil
+	}
+
+	invalidateVKCaches()
+	return nil
+}
+
+// invalidateVKCaches сбрасывает кэши отчётов, которые зависят от стены VK
+func invalidateVKCaches() {
+	for _, prefix := range vkCachePrefixes {
+		dataCache.DeletePrefix(prefix)
+	}
+}